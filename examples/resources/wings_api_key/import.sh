@@ -0,0 +1,3 @@
+# API keys are imported by their ID. The secret is only returned when a key is
+# created, so it is null after import.
+terraform import wings_api_key.example key_3f9a2c
@@ -0,0 +1,2 @@
+# Context schemas are imported by their key.
+terraform import wings_context_schema.example default
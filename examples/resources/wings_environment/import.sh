@@ -0,0 +1,2 @@
+# Environments are imported by project/key.
+terraform import wings_environment.example payments/staging
@@ -0,0 +1,2 @@
+# Projects are imported by their key.
+terraform import wings_project.example payments
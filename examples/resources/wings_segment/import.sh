@@ -0,0 +1,2 @@
+# Segments are imported by their key.
+terraform import wings_segment.example internal
@@ -0,0 +1,3 @@
+# Segment lists are imported by their key. Their members are not imported, so
+# the first plan after an import shows members or members_file being set.
+terraform import wings_segment_list.example beta
@@ -0,0 +1,6 @@
+# Values are imported by their value_id, from the provider's project and
+# environment if it has them.
+terraform import wings_value.example my-value
+
+# Values in a project are imported by project/environment/value_id.
+terraform import wings_value.example payments/prod/my-value
@@ -0,0 +1,3 @@
+# Overrides are imported by the ID of their base value, followed by the
+# environment they apply to.
+terraform import wings_value_environment_override.example payments/staging/new-checkout/prod
@@ -0,0 +1,2 @@
+# Targeting rules are imported by the ID of their value, followed by their name.
+terraform import wings_value_targeting_rule.example payments/staging/new-checkout/beta-testers
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type APIKey struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Project     string     `json:"project,omitempty"`
+	Environment string     `json:"environment,omitempty"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+
+	// Secret is only returned when the key is created.
+	Secret string `json:"secret,omitempty"`
+
+	// CreatedAt and Revoked are set by the server, and left empty in the
+	// keys the provider creates and updates. Keys are revoked by deleting them.
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	Revoked   bool       `json:"revoked,omitempty"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// Capabilities describe the version of a Wings server and the optional
+// features it supports.
+type Capabilities struct {
+	Version      string   `json:"version"`
+	Capabilities []string `json:"capabilities"`
+}
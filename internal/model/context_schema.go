@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// ContextSchema declares the attributes of the evaluation context and their
+// types, which the variables of expressions and tests refer to.
+type ContextSchema struct {
+	Key         string             `json:"key"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Attributes  []ContextAttribute `json:"attributes"`
+
+	// UpdatedAt is set by the server. Schemas are written from their
+	// configuration, which leaves it empty.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// ContextAttribute is an attribute of the evaluation context.
+type ContextAttribute struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
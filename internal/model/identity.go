@@ -0,0 +1,8 @@
+package model
+
+// Identity is the principal that the credentials of a request authenticate as.
+type Identity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
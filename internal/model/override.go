@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// ValueOverride layers settings for one environment onto a base value. Unset
+// settings are inherited from the base value.
+type ValueOverride struct {
+	Enabled        *bool      `json:"enabled,omitempty"`
+	DefaultVariant *string    `json:"defaultVariant,omitempty"`
+	Targeting      *Targeting `json:"targeting,omitempty"`
+
+	// UpdatedAt and UpdatedBy are set by the server on every write and left
+	// empty in the overrides the provider sends.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string     `json:"updatedBy,omitempty"`
+}
@@ -0,0 +1,16 @@
+package model
+
+type Project struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Ordering    *int64 `json:"ordering,omitempty"`
+}
+
+type Environment struct {
+	Project     string `json:"project"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Ordering    *int64 `json:"ordering,omitempty"`
+}
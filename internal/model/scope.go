@@ -0,0 +1,12 @@
+package model
+
+// Scope is the project and environment a value lives in. The zero Scope is
+// the flat namespace of servers without projects.
+type Scope struct {
+	Project     string
+	Environment string
+}
+
+func (s Scope) IsZero() bool {
+	return s.Project == "" && s.Environment == ""
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Segment is a named audience that targeting rules can reference instead of
+// repeating its expression.
+type Segment struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// A segment either has an Expr, or Rules that all or any of must match,
+	// depending on Match.
+	Expr  string   `json:"expr,omitempty"`
+	Rules []string `json:"rules,omitempty"`
+	Match string   `json:"match,omitempty"`
+
+	// UpdatedAt is set by the server and left empty on writes.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// SegmentList is a segment defined by an explicit list of members, such as
+// the user IDs of a beta program. Its members are managed separately, as
+// they can be too many to send in a single request.
+type SegmentList struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// UpdatedAt is set by the server and left empty on writes, which only
+	// send the name and description.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// SegmentListMembers is a page of the members of a segment list.
+type SegmentListMembers struct {
+	Members    []string `json:"members"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// SegmentListChanges adds and removes members of a segment list.
+type SegmentListChanges struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type Value struct {
 	ID             string            `json:"id"`
 	Enabled        bool              `json:"enabled"`
@@ -8,6 +10,30 @@ type Value struct {
 	Variants       Variants          `json:"variants"`
 	Targeting      Targeting         `json:"targeting"`
 	Tests          []*EvaluationTest `json:"tests,omitempty"`
+
+	// Prerequisites must all serve their variant for the value to be
+	// evaluated. Otherwise it serves its default variant.
+	Prerequisites []ValuePrerequisite `json:"prerequisites,omitempty"`
+
+	// Project and Environment scope the value. Both are empty for values in
+	// the flat namespace of servers without projects.
+	Project     string `json:"project,omitempty"`
+	Environment string `json:"environment,omitempty"`
+
+	// CreatedAt, UpdatedAt and UpdatedBy are set by the server. ModifyValue
+	// clears them from the value it read before writing it back.
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string     `json:"updatedBy,omitempty"`
+
+	// Revision is maintained by the server too, but is sent on
+	// read-modify-write updates, which the server rejects with 409 Conflict
+	// if the value changed since it was read.
+	Revision int64 `json:"revision,omitempty"`
+}
+
+func (v *Value) Scope() Scope {
+	return Scope{Project: v.Project, Environment: v.Environment}
 }
 
 type (
@@ -38,6 +64,13 @@ type (
 	}
 )
 
+// ValuePrerequisite requires the value ValueID, in the same project and
+// environment, to serve Variant.
+type ValuePrerequisite struct {
+	ValueID string `json:"valueId"`
+	Variant string `json:"variant"`
+}
+
 type EvaluationTest struct {
 	Variables map[string]any `json:"variables"`
 	Expected  string         `json:"expected"`
@@ -49,7 +82,17 @@ type Targeting struct {
 
 type ValueTargetingRule struct {
 	Variant string `json:"variant"`
-	Expr    string `json:"expr"`
+
+	// A rule matches either Expr, the segment with the key Segment, or the
+	// members of the segment list with the key SegmentList.
+	Expr        string `json:"expr,omitempty"`
+	Segment     string `json:"segment,omitempty"`
+	SegmentList string `json:"segmentList,omitempty"`
+
+	// Name and Priority identify and order rules that are managed separately
+	// from the value they belong to. Both are empty for the value's own rules.
+	Name     string `json:"name,omitempty"`
+	Priority int64  `json:"priority,omitempty"`
 }
 
 type ValueTransform struct {
@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	headerAuthorization = "Authorization"
+)
+
+const (
+	authMethodAPIKey            = "api_key"
+	authMethodBearerToken       = "bearer_token"
+	authMethodOAuth2            = "oauth2"
+	authMethodCredentialProcess = "credential_process"
+)
+
+const (
+	envBearerToken        = "WINGS_BEARER_TOKEN"
+	envOAuth2ClientSecret = "WINGS_OAUTH2_CLIENT_SECRET"
+)
+
+// credentialRefreshWindow is how long before expiry cached short-lived
+// credentials are replaced, so that they do not expire while a request is in
+// flight.
+const credentialRefreshWindow = time.Minute
+
+type wingsProviderOAuth2Model struct {
+	TokenURL     types.String   `tfsdk:"token_url"`
+	ClientID     types.String   `tfsdk:"client_id"`
+	ClientSecret types.String   `tfsdk:"client_secret"`
+	Scopes       []types.String `tfsdk:"scopes"`
+}
+
+// authenticator adds credentials to requests sent to the Wings API.
+type authenticator interface {
+	authenticate(ctx context.Context, req *http.Request) error
+}
+
+// selectAuthMethod picks the authentication method from the provider
+// configuration. OAuth2 takes precedence over a bearer token, which takes
+// precedence over API keys, which take precedence over a credential process.
+// Configuring more than one method explicitly is an error, but credentials
+// from the environment or the shared credentials file do not conflict with an
+// explicitly configured method. Without one, the method is taken from the
+// source with the highest precedence that has credentials, so that API keys
+// in the environment win over a bearer token in the shared credentials file.
+func selectAuthMethod(cfg *wingsProviderModel, creds *credentials) (string, error) {
+	var configured []string
+	if cfg.OAuth2 != nil {
+		configured = append(configured, "oauth2")
+	}
+	if !cfg.BearerToken.IsNull() {
+		configured = append(configured, "bearer_token")
+	}
+	if !cfg.APIKeyID.IsNull() || !cfg.APIKey.IsNull() {
+		configured = append(configured, "api_key_id/api_key")
+	}
+	if !cfg.CredentialProcess.IsNull() {
+		configured = append(configured, "credential_process")
+	}
+	if len(configured) > 1 {
+		return "", fmt.Errorf("only one authentication method may be configured, got %s", strings.Join(configured, ", "))
+	}
+
+	switch {
+	case cfg.OAuth2 != nil:
+		return authMethodOAuth2, nil
+	case !cfg.CredentialProcess.IsNull():
+		return authMethodCredentialProcess, nil
+	case !cfg.APIKeyID.IsNull() || !cfg.APIKey.IsNull():
+		return authMethodAPIKey, nil
+	}
+
+	// Methods are listed in order of precedence, which breaks ties between
+	// credentials from the same source.
+	methods := []struct {
+		method string
+		set    bool
+		source credentialSource
+	}{
+		{authMethodBearerToken, creds.BearerToken != "", creds.sources.BearerToken},
+		{authMethodAPIKey, creds.APIKeyID != "" || creds.APIKey != "", max(creds.sources.APIKeyID, creds.sources.APIKey)},
+		{authMethodCredentialProcess, creds.CredentialProcess != "", creds.sources.CredentialProcess},
+	}
+	method, best := authMethodAPIKey, credentialSource(-1)
+	for _, m := range methods {
+		if m.set && m.source > best {
+			method, best = m.method, m.source
+		}
+	}
+	return method, nil
+}
+
+// apiKeySource supplies the API key used to authenticate.
+type apiKeySource interface {
+	apiKey(ctx context.Context) (keyID, key string, err error)
+}
+
+type staticAPIKey struct {
+	keyID string
+	key   string
+}
+
+func (s *staticAPIKey) apiKey(context.Context) (string, string, error) {
+	return s.keyID, s.key, nil
+}
+
+type apiKeyAuth struct {
+	source apiKeySource
+}
+
+func (a *apiKeyAuth) authenticate(ctx context.Context, req *http.Request) error {
+	keyID, key, err := a.source.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(headerKeyID, keyID)
+	req.Header.Set(headerKey, key)
+	return nil
+}
+
+type bearerTokenAuth struct {
+	token string
+}
+
+func (a *bearerTokenAuth) authenticate(_ context.Context, req *http.Request) error {
+	req.Header.Set(headerAuthorization, "Bearer "+a.token)
+	return nil
+}
+
+// oauth2ClientCredentialsAuth obtains access tokens with the OAuth2 client
+// credentials grant (RFC 6749, section 4.4) and caches them until shortly
+// before they expire.
+type oauth2ClientCredentialsAuth struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+	now          func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	// refreshing is closed when the token request in flight completes, or
+	// nil if there is none.
+	refreshing chan struct{}
+}
+
+func newOAuth2ClientCredentialsAuth(m *wingsProviderOAuth2Model, clientSecret string, client *http.Client) *oauth2ClientCredentialsAuth {
+	scopes := make([]string, 0, len(m.Scopes))
+	for _, s := range m.Scopes {
+		scopes = append(scopes, s.ValueString())
+	}
+	return &oauth2ClientCredentialsAuth{
+		tokenURL:     m.TokenURL.ValueString(),
+		clientID:     m.ClientID.ValueString(),
+		clientSecret: clientSecret,
+		scopes:       scopes,
+		client:       client,
+		now:          time.Now,
+	}
+}
+
+func (a *oauth2ClientCredentialsAuth) authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(headerAuthorization, "Bearer "+token)
+	return nil
+}
+
+// accessToken returns the cached access token, or requests a new one. The
+// lock is not held during the token request, so that concurrent callers wait
+// for the request in flight instead of blocking on the lock.
+func (a *oauth2ClientCredentialsAuth) accessToken(ctx context.Context) (string, error) {
+	for {
+		a.mu.Lock()
+		if a.token != "" && (a.expiry.IsZero() || a.now().Add(credentialRefreshWindow).Before(a.expiry)) {
+			token := a.token
+			a.mu.Unlock()
+			return token, nil
+		}
+		if refreshing := a.refreshing; refreshing != nil {
+			a.mu.Unlock()
+			select {
+			case <-refreshing:
+				continue
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+		refreshing := make(chan struct{})
+		a.refreshing = refreshing
+		a.mu.Unlock()
+
+		token, expiry, err := a.requestToken(ctx)
+
+		a.mu.Lock()
+		if err == nil {
+			a.token, a.expiry = token, expiry
+		}
+		a.refreshing = nil
+		a.mu.Unlock()
+		close(refreshing)
+		return token, err
+	}
+}
+
+// requestToken requests a new access token, and returns it with its expiry,
+// which is zero if the token does not expire.
+func (a *oauth2ClientCredentialsAuth) requestToken(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+
+	issued := a.now()
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("requesting OAuth2 access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", time.Time{}, fmt.Errorf("requesting OAuth2 access token: %w", newAPIError(resp))
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, fmt.Errorf("decoding OAuth2 token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("OAuth2 token response did not contain an access token")
+	}
+	if body.TokenType != "" && !strings.EqualFold(body.TokenType, "bearer") {
+		return "", time.Time{}, fmt.Errorf("unsupported OAuth2 token type %q", body.TokenType)
+	}
+
+	var expiry time.Time
+	if body.ExpiresIn > 0 {
+		expiry = issued.Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return body.AccessToken, expiry, nil
+}
@@ -0,0 +1,359 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// newTestTokenServer stands in for an OAuth2 authorization server, issuing
+// numbered tokens that expire after expiresIn seconds.
+func newTestTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var issued atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing token request: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("grant_type = %q, want client_credentials", got)
+		}
+		if got := r.PostForm.Get("scope"); got != "values:read values:write" {
+			t.Errorf("scope = %q, want values:read values:write", got)
+		}
+
+		n := issued.Add(1)
+		w.Header().Set(headerContentType, applicationJSON)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &issued
+}
+
+func testOAuth2Auth(tokenURL, secret string) *oauth2ClientCredentialsAuth {
+	return newOAuth2ClientCredentialsAuth(&wingsProviderOAuth2Model{
+		TokenURL: types.StringValue(tokenURL),
+		ClientID: types.StringValue("client"),
+		Scopes: []types.String{
+			types.StringValue("values:read"),
+			types.StringValue("values:write"),
+		},
+	}, secret, http.DefaultClient)
+}
+
+func authorization(t *testing.T, auth authenticator) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8018/values", nil)
+	if err := auth.authenticate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	return req.Header.Get(headerAuthorization)
+}
+
+func Test_OAuth2ClientCredentialsAuth(t *testing.T) {
+	t.Parallel()
+
+	srv, issued := newTestTokenServer(t, 3600)
+	auth := testOAuth2Auth(srv.URL, "s3cret")
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+
+	if got := authorization(t, auth); got != "Bearer token-1" {
+		t.Errorf("authorization = %q, want Bearer token-1", got)
+	}
+	if got := authorization(t, auth); got != "Bearer token-1" {
+		t.Errorf("authorization = %q, want the cached token", got)
+	}
+
+	// Close to expiry the token is refreshed before it is used again.
+	now = now.Add(time.Hour - credentialRefreshWindow/2)
+	if got := authorization(t, auth); got != "Bearer token-2" {
+		t.Errorf("authorization = %q, want the refreshed token-2", got)
+	}
+	if n := issued.Load(); n != 2 {
+		t.Errorf("issued %d tokens, want 2", n)
+	}
+}
+
+func Test_OAuth2ClientCredentialsAuth_Error(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := newTestTokenServer(t, 3600)
+	auth := testOAuth2Auth(srv.URL, "wrong")
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8018/values", nil)
+	err := auth.authenticate(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Fatalf("error = %v, want invalid_client", err)
+	}
+	if req.Header.Get(headerAuthorization) != "" {
+		t.Error("authorization header set despite the token request failing")
+	}
+}
+
+func Test_OAuth2ClientCredentialsAuth_Retry(t *testing.T) {
+	t.Parallel()
+
+	// The token endpoint fails once before issuing a token.
+	srv, issued := newTestTokenServer(t, 3600)
+	var tokenRequests atomic.Int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenRequests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(flaky.Close)
+
+	// The API fails once too, so that its retry is authenticated again.
+	var apiRequests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiRequests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if got := r.Header.Get(headerAuthorization); got != "Bearer token-1" {
+			t.Errorf("authorization = %q, want Bearer token-1", got)
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	tokenClient := newRetryClient(http.DefaultTransport)
+	tokenClient.RetryWaitMin, tokenClient.RetryWaitMax = time.Millisecond, time.Millisecond
+	auth := testOAuth2Auth(flaky.URL, "s3cret")
+	auth.client = tokenClient.StandardClient()
+
+	apiClient := newRetryClient(http.DefaultTransport)
+	apiClient.RetryWaitMin, apiClient.RetryWaitMax = time.Millisecond, time.Millisecond
+	authenticateRetries(apiClient, api.URL, auth)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.URL+"/values", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authenticate(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := apiClient.StandardClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if n := tokenRequests.Load(); n != 2 {
+		t.Errorf("token requests = %d, want 2", n)
+	}
+	if n := issued.Load(); n != 1 {
+		t.Errorf("issued %d tokens, want 1", n)
+	}
+	if n := apiRequests.Load(); n != 2 {
+		t.Errorf("API requests = %d, want 2", n)
+	}
+}
+
+func Test_OAuth2ClientCredentialsAuth_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	srv, issued := newTestTokenServer(t, 3600)
+	auth := testOAuth2Auth(srv.URL, "s3cret")
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := auth.accessToken(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := issued.Load(); n != 1 {
+		t.Errorf("issued %d tokens, want 1", n)
+	}
+}
+
+func Test_StaticAuth(t *testing.T) {
+	t.Parallel()
+
+	if got := authorization(t, &bearerTokenAuth{token: "abc"}); got != "Bearer abc" {
+		t.Errorf("authorization = %q, want Bearer abc", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8018/values", nil)
+	if err := (&apiKeyAuth{source: &staticAPIKey{keyID: "id", key: "key"}}).authenticate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get(headerKeyID) != "id" || req.Header.Get(headerKey) != "key" {
+		t.Errorf("api key headers = %q, %q", req.Header.Get(headerKeyID), req.Header.Get(headerKey))
+	}
+}
+
+func Test_SelectAuthMethod(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     func(*wingsProviderModel)
+		creds   credentials
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "api key",
+			creds: credentials{APIKeyID: "id", APIKey: "key"},
+			want:  authMethodAPIKey,
+		},
+		{
+			name:  "bearer token from environment",
+			creds: credentials{BearerToken: "token"},
+			want:  authMethodBearerToken,
+		},
+		{
+			name: "configured api key wins over bearer token from environment",
+			cfg: func(m *wingsProviderModel) {
+				m.APIKeyID = types.StringValue("id")
+				m.APIKey = types.StringValue("key")
+			},
+			creds: credentials{APIKeyID: "id", APIKey: "key", BearerToken: "token"},
+			want:  authMethodAPIKey,
+		},
+		{
+			name: "oauth2",
+			cfg: func(m *wingsProviderModel) {
+				m.OAuth2 = &wingsProviderOAuth2Model{}
+			},
+			creds: credentials{APIKeyID: "id", APIKey: "key", BearerToken: "token"},
+			want:  authMethodOAuth2,
+		},
+		{
+			name:  "credential process from shared credentials file",
+			creds: credentials{CredentialProcess: "vault-creds"},
+			want:  authMethodCredentialProcess,
+		},
+		{
+			name: "configured credential process",
+			cfg: func(m *wingsProviderModel) {
+				m.CredentialProcess = types.StringValue("vault-creds")
+			},
+			creds: credentials{APIKeyID: "id", APIKey: "key", CredentialProcess: "vault-creds"},
+			want:  authMethodCredentialProcess,
+		},
+		{
+			name: "conflicting methods",
+			cfg: func(m *wingsProviderModel) {
+				m.OAuth2 = &wingsProviderOAuth2Model{}
+				m.BearerToken = types.StringValue("token")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := nullProviderModel()
+			if tt.cfg != nil {
+				tt.cfg(cfg)
+			}
+			got, err := selectAuthMethod(cfg, &tt.creds)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("selectAuthMethod() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("selectAuthMethod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SelectAuthMethod_Precedence(t *testing.T) {
+	t.Parallel()
+
+	file := writeSharedCredentials(t, `
+[default]
+endpoint     = https://file.example.com
+bearer_token = file_token
+
+[keys]
+api_key_id = file_key_id
+api_key    = file_key
+`)
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "api keys from environment win over bearer token from file",
+			env:  map[string]string{envAPIKeyID: "env_key_id", envAPIKey: "env_key"},
+			want: authMethodAPIKey,
+		},
+		{
+			name: "bearer token from environment wins over api keys from file",
+			env:  map[string]string{envProfile: "keys", envBearerToken: "env_token"},
+			want: authMethodBearerToken,
+		},
+		{
+			name: "credential process from environment wins over bearer token from file",
+			env:  map[string]string{envCredentialProcess: "vault-creds"},
+			want: authMethodCredentialProcess,
+		},
+		{
+			name: "bearer token wins over api keys from the same source",
+			env:  map[string]string{envAPIKeyID: "env_key_id", envAPIKey: "env_key", envBearerToken: "env_token"},
+			want: authMethodBearerToken,
+		},
+		{
+			name: "bearer token from file",
+			want: authMethodBearerToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := map[string]string{"HOME": t.TempDir(), envSharedCredentialsFile: file}
+			for k, v := range tt.env {
+				env[k] = v
+			}
+			cfg := nullProviderModel()
+			creds, err := resolveCredentials(cfg, testEnv(env))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := selectAuthMethod(cfg, creds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("selectAuthMethod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
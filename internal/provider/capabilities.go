@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// Capabilities of the server that features of the provider depend on.
+const (
+	capabilityProjects = "projects"
+	capabilityAPIKeys  = "api_keys"
+
+	capabilityEnvironmentOverrides = "environment_overrides"
+	capabilityNamedTargetingRules  = "named_targeting_rules"
+	capabilitySegments             = "segments"
+	capabilitySegmentLists         = "segment_lists"
+	capabilityContextSchemas       = "context_schemas"
+	capabilityPrerequisites        = "prerequisites"
+)
+
+// serverCapabilities caches the capabilities of the server for the run.
+type serverCapabilities struct {
+	mu   sync.Mutex
+	caps *model.Capabilities
+	// fetching is closed when the request in flight completes, or nil if
+	// there is none.
+	fetching chan struct{}
+}
+
+// Capabilities returns the capabilities of the server, fetching them until
+// they have been read once. Errors are not cached, so that a transient
+// failure does not fail every later check of the run. The lock is not held
+// during the request, so that concurrent callers wait for the request in
+// flight for only as long as their context allows. Servers that predate the
+// capabilities endpoint have no capabilities and an empty version.
+func (c *config) Capabilities(ctx context.Context) (*model.Capabilities, error) {
+	s := &c.capabilities
+	for {
+		s.mu.Lock()
+		if s.caps != nil {
+			caps := s.caps
+			s.mu.Unlock()
+			return caps, nil
+		}
+		if fetching := s.fetching; fetching != nil {
+			s.mu.Unlock()
+			select {
+			case <-fetching:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		fetching := make(chan struct{})
+		s.fetching = fetching
+		s.mu.Unlock()
+
+		caps, err := c.fetchCapabilities(ctx)
+		if err == nil {
+			tflog.Debug(ctx, "Read Wings server capabilities", map[string]any{
+				"wings_server_version": caps.Version,
+				"wings_capabilities":   caps.Capabilities,
+			})
+		}
+
+		s.mu.Lock()
+		if err == nil {
+			s.caps = caps
+		}
+		s.fetching = nil
+		s.mu.Unlock()
+		close(fetching)
+		if err != nil {
+			return nil, err
+		}
+		return caps, nil
+	}
+}
+
+func (c *config) fetchCapabilities(ctx context.Context) (*model.Capabilities, error) {
+	u, err := url.JoinPath(c.endpoint, "capabilities")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &model.Capabilities{}, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp)
+	}
+
+	caps := new(model.Capabilities)
+	err = json.NewDecoder(resp.Body).Decode(caps)
+	return caps, err
+}
+
+// requireCapability adds an error on attr to diags if the server does not
+// have capability, which the feature described by feature depends on.
+func (c *config) requireCapability(ctx context.Context, capability, feature string, attr path.Path, diags *diag.Diagnostics) {
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		diags.AddError(
+			"Unable to Read Wings Server Capabilities",
+			fmt.Sprintf("The provider needs the capabilities of the Wings server to check whether it supports %s: %s", feature, err),
+		)
+		return
+	}
+	if slices.Contains(caps.Capabilities, capability) {
+		return
+	}
+
+	version := caps.Version
+	if version == "" {
+		version = "of an unknown version"
+	}
+	diags.AddAttributeError(
+		attr,
+		"Unsupported Wings Server Feature",
+		fmt.Sprintf("The Wings server %s at %s does not support %s, as it lacks the %q capability. "+
+			"Upgrade the server or remove %s from the configuration.", version, c.endpoint, feature, capability, feature),
+	)
+}
@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func newCapabilitiesTestConfig(t *testing.T, status int, body string) (*config, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/capabilities" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		requests.Add(1)
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return &config{endpoint: srv.URL, client: srv.Client()}, &requests
+}
+
+func Test_RequireCapability(t *testing.T) {
+	t.Parallel()
+
+	c, requests := newCapabilitiesTestConfig(t, http.StatusOK, `{"version":"1.3.0","capabilities":["projects"]}`)
+
+	var diags diag.Diagnostics
+	c.requireCapability(context.Background(), "projects", "projects", path.Root("project"), &diags)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	c.requireCapability(context.Background(), "rollouts", "fractional rollout", path.Root("rollout"), &diags)
+	if !diags.HasError() {
+		t.Fatal("missing capability not reported")
+	}
+	if got := diags[0].Detail(); !strings.Contains(got, "Wings server 1.3.0") || !strings.Contains(got, "does not support fractional rollout") {
+		t.Errorf("detail = %q", got)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("capabilities requested %d times, want once per run", n)
+	}
+}
+
+func Test_RequireCapability_LegacyServer(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newCapabilitiesTestConfig(t, http.StatusNotFound, `404 page not found`)
+
+	caps, err := c.Capabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Version != "" || len(caps.Capabilities) != 0 {
+		t.Errorf("Capabilities() = %+v, want none", caps)
+	}
+
+	var diags diag.Diagnostics
+	c.requireCapability(context.Background(), "projects", "projects", path.Root("project"), &diags)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail(), "of an unknown version") {
+		t.Errorf("diagnostics = %v, want the legacy server to be reported", diags)
+	}
+}
+
+func Test_RequireCapability_Error(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newCapabilitiesTestConfig(t, http.StatusUnauthorized, `{"error":"invalid api key"}`)
+
+	var diags diag.Diagnostics
+	c.requireCapability(context.Background(), "projects", "projects", path.Root("project"), &diags)
+	if !diags.HasError() || diags[0].Summary() != "Unable to Read Wings Server Capabilities" {
+		t.Errorf("diagnostics = %v", diags)
+	}
+}
+
+func Test_Capabilities_RetryAfterError(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid api key"}`)
+			return
+		}
+		fmt.Fprint(w, `{"version":"1.3.0","capabilities":["projects"]}`)
+	}))
+	t.Cleanup(srv.Close)
+	c := &config{endpoint: srv.URL, client: srv.Client()}
+
+	if _, err := c.Capabilities(context.Background()); err == nil {
+		t.Fatal("Capabilities() succeeded, want the first request to fail")
+	}
+	for range 2 {
+		caps, err := c.Capabilities(context.Background())
+		if err != nil {
+			t.Fatalf("Capabilities() after a failure: %v", err)
+		}
+		if caps.Version != "1.3.0" {
+			t.Errorf("Capabilities() = %+v", caps)
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("capabilities requested %d times, want again after the failure only", n)
+	}
+}
+
+func Test_Capabilities_WaitHonorsContext(t *testing.T) {
+	t.Parallel()
+
+	var (
+		requests atomic.Int32
+		received = make(chan struct{})
+		release  = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(received)
+		}
+		<-release
+		fmt.Fprint(w, `{"version":"1.3.0","capabilities":["projects"]}`)
+	}))
+	t.Cleanup(srv.Close)
+	c := &config{endpoint: srv.URL, client: srv.Client()}
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.Capabilities(context.Background())
+		first <- err
+	}()
+	<-received
+
+	// A caller waiting for the request in flight gives up with its context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Capabilities(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Capabilities() with a cancelled context = %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Capabilities(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("capabilities requested %d times, want once", n)
+	}
+}
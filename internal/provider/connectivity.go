@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// newRetryClient returns a client that retries requests over rt according to
+// checkRetry.
+func newRetryClient(rt http.RoundTripper) *retryablehttp.Client {
+	retryClient := retryablehttp.NewClient()
+	retryClient.RetryMax = 5
+	retryClient.CheckRetry = checkRetry
+	retryClient.HTTPClient.Transport = rt
+	return retryClient
+}
+
+// authenticateRetries authenticates every retry of a request to endpoint
+// again, so that retries get fresh signatures and tokens. Requests to other
+// URLs, such as an OAuth2 token endpoint, keep their own credentials.
+func authenticateRetries(retryClient *retryablehttp.Client, endpoint string, auth authenticator) {
+	retryClient.PrepareRetry = func(req *http.Request) error {
+		if !strings.HasPrefix(req.URL.String(), strings.TrimSuffix(endpoint, "/")+"/") && req.URL.String() != endpoint {
+			return nil
+		}
+		return auth.authenticate(req.Context(), req)
+	}
+}
+
+// checkRetry is the retry policy of the client. It does not retry failed DNS
+// lookups of hosts that do not exist, as these are configuration mistakes.
+func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false, err
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
+
+// describeConnectionError returns the summary and detail of a diagnostic
+// explaining why a request to endpoint failed.
+func describeConnectionError(endpoint string, err error) (summary, detail string) {
+	var (
+		dnsErr       *net.DNSError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		opErr        *net.OpError
+		apiErr       *apiError
+	)
+	isOpErr := errors.As(err, &opErr)
+	switch {
+	case errors.As(err, &dnsErr):
+		return "Wings API Endpoint Not Found",
+			fmt.Sprintf("The host of the Wings endpoint %s could not be resolved. Check the endpoint for typos: %s", endpoint, err)
+	case errors.As(err, &verifyErr), errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return "Wings Server Certificate Not Trusted",
+			fmt.Sprintf("The certificate of the Wings endpoint %s could not be verified. "+
+				"If it is issued by an internal CA, trust it with ca_cert_pem or ca_cert_file: %s", endpoint, err)
+	case errors.As(err, &recordErr), strings.Contains(err.Error(), "server gave HTTP response to HTTPS client"):
+		return "Wings TLS Handshake Failed",
+			fmt.Sprintf("The Wings endpoint %s did not answer with TLS. Check whether it should use http instead of https: %s", endpoint, err)
+	case errors.As(err, &alertErr), isOpErr && opErr.Op == "remote error":
+		return "Wings TLS Handshake Failed",
+			fmt.Sprintf("The Wings endpoint %s rejected the TLS handshake. "+
+				"Check whether it requires a client certificate or a different TLS version: %s", endpoint, err)
+	case isUnauthorized(err):
+		return "Invalid Wings Credentials",
+			fmt.Sprintf("The Wings endpoint %s rejected the credentials. Check that they are correct and have not expired or been revoked: %s", endpoint, err)
+	case isForbidden(err):
+		return "Insufficient Wings Permissions",
+			fmt.Sprintf("The credentials are valid, but not allowed to use the Wings endpoint %s: %s", endpoint, err)
+	case isNotFound(err):
+		return "Wings API Not Found",
+			fmt.Sprintf("The Wings endpoint %s does not serve the Wings API. Check the path of the endpoint: %s", endpoint, err)
+	case errors.As(err, &apiErr):
+		return "Unexpected Wings API Response",
+			fmt.Sprintf("The Wings endpoint %s failed to validate the credentials: %s", endpoint, err)
+	case isOpErr:
+		return "Wings API Unreachable",
+			fmt.Sprintf("The Wings endpoint %s could not be reached: %s", endpoint, err)
+	default:
+		return "Unable to Validate Wings Credentials",
+			fmt.Sprintf("The credentials could not be validated against the Wings endpoint %s: %s", endpoint, err)
+	}
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/jarcoal/httpmock"
+)
+
+func Test_DescribeConnectionError(t *testing.T) {
+	t.Parallel()
+
+	pki := newTestPKI(t)
+	tlsServer := newTestTLSServer(t, pki, nil)
+	mutual := newTestTLSServer(t, pki, func(c *tls.Config) {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+		c.ClientCAs = pki.pool
+	})
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/whoami" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Header.Get(headerKey) {
+		case "valid":
+			fmt.Fprint(w, `{"id":"key-1","name":"terraform","type":"api_key"}`)
+		case "read-only":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(plain.Close)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	caOnly, err := newTransport(&transportOptions{caCertPEM: pki.caPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noHost := &http.Transport{
+		DialContext: func(context.Context, string, string) (net.Conn, error) {
+			return nil, &net.DNSError{Err: "no such host", Name: "wings.invalid", IsNotFound: true}
+		},
+	}
+
+	tests := []struct {
+		name      string
+		endpoint  string
+		transport http.RoundTripper
+		key       string
+		want      string
+	}{
+		{name: "unknown host", endpoint: "http://wings.invalid", transport: noHost, want: "Wings API Endpoint Not Found"},
+		{name: "untrusted certificate", endpoint: tlsServer.URL, want: "Wings Server Certificate Not Trusted"},
+		{name: "plain http server", endpoint: "https://" + plain.Listener.Addr().String(), want: "Wings TLS Handshake Failed"},
+		{name: "missing client certificate", endpoint: mutual.URL, transport: caOnly, want: "Wings TLS Handshake Failed"},
+		{name: "connection refused", endpoint: closed.URL, want: "Wings API Unreachable"},
+		{name: "invalid key", endpoint: plain.URL, key: "invalid", want: "Invalid Wings Credentials"},
+		{name: "forbidden", endpoint: plain.URL, key: "read-only", want: "Insufficient Wings Permissions"},
+		{name: "wrong path", endpoint: plain.URL + "/api/v2", key: "valid", want: "Wings API Not Found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			transport := tt.transport
+			if transport == nil {
+				transport = http.DefaultTransport.(*http.Transport).Clone()
+			}
+			c := &config{
+				endpoint: tt.endpoint,
+				client:   &http.Client{Transport: transport},
+				auth:     &apiKeyAuth{source: &staticAPIKey{keyID: "id", key: tt.key}},
+			}
+			_, err := c.WhoAmI(context.Background())
+			if err == nil {
+				t.Fatal("WhoAmI() succeeded, want error")
+			}
+			if summary, detail := describeConnectionError(tt.endpoint, err); summary != tt.want {
+				t.Errorf("summary = %q, want %q (%s)", summary, tt.want, detail)
+			}
+		})
+	}
+
+	c := &config{
+		endpoint: plain.URL,
+		client:   plain.Client(),
+		auth:     &apiKeyAuth{source: &staticAPIKey{keyID: "id", key: "valid"}},
+	}
+	identity, err := c.WhoAmI(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.ID != "key-1" || identity.Name != "terraform" || identity.Type != "api_key" {
+		t.Errorf("WhoAmI() = %+v", identity)
+	}
+}
+
+func Test_CheckRetry(t *testing.T) {
+	t.Parallel()
+
+	retry, _ := checkRetry(context.Background(), nil, &net.DNSError{Name: "wings.invalid", IsNotFound: true})
+	if retry {
+		t.Error("retrying an unknown host")
+	}
+	retry, _ = checkRetry(context.Background(), nil, &net.DNSError{Name: "wings.example.com", IsTemporary: true})
+	if !retry {
+		t.Error("not retrying a temporary DNS failure")
+	}
+	retry, _ = checkRetry(context.Background(), nil, errors.New("connection reset by peer"))
+	if !retry {
+		t.Error("not retrying a connection error")
+	}
+}
+
+func TestAccProvider_ValidateCredentials(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/whoami",
+		httpmock.NewStringResponder(401, `{"error":"invalid api key"}`),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client:   &http.Client{Transport: mock},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "wings" {
+  endpoint             = "http://localhost:8018"
+  api_key              = "test_key"
+  api_key_id           = "test_key_id"
+  validate_credentials = true
+}
+` + testAccResourceBool(),
+				ExpectError: regexp.MustCompile(`Invalid Wings Credentials`),
+			},
+		},
+	})
+}
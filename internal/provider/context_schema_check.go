@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Types of the attributes of the evaluation context.
+const (
+	contextTypeString    = "string"
+	contextTypeInt       = "int"
+	contextTypeDouble    = "double"
+	contextTypeBool      = "bool"
+	contextTypeTimestamp = "timestamp"
+	contextTypeList      = "list"
+)
+
+var contextTypes = []string{
+	contextTypeString,
+	contextTypeInt,
+	contextTypeDouble,
+	contextTypeBool,
+	contextTypeTimestamp,
+	contextTypeList,
+}
+
+// contextSchemaDefinition is the definition of a context schema, which
+// wings_value references in context_schema to type-check its expressions and
+// tests at plan time.
+type contextSchemaDefinition struct {
+	Key        string            `json:"key"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+func parseContextSchemaDefinition(s string) (*contextSchemaDefinition, error) {
+	d := new(contextSchemaDefinition)
+	if err := json.Unmarshal([]byte(s), d); err != nil {
+		return nil, fmt.Errorf("context_schema must be the definition of a wings_context_schema: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(d.Attributes)) {
+		if !slices.Contains(contextTypes, d.Attributes[name]) {
+			return nil, fmt.Errorf("attribute %s of context_schema has type %q; expected one of %s",
+				name, d.Attributes[name], strings.Join(contextTypes, ", "))
+		}
+	}
+	return d, nil
+}
+
+// literalTypes are the context types of the kinds of literals.
+var literalTypes = map[int]string{
+	tokenString: contextTypeString,
+	tokenInt:    contextTypeInt,
+	tokenDouble: contextTypeDouble,
+	tokenBool:   contextTypeBool,
+}
+
+// Macros whose first argument declares a variable of the expression.
+var exprMacros = []string{"all", "exists", "exists_one", "map", "filter"}
+
+// Methods that only strings have.
+var stringMethods = []string{"contains", "endsWith", "matches", "startsWith"}
+
+// checkExpr returns the problems with the use of context attributes in expr:
+// attributes the schema does not declare, attributes compared with literals
+// of another type, and string methods called on attributes of other types.
+// Fields are identifiers expr may use besides context attributes, such as
+// the fields of the object a transform applies to. It is a best-effort check
+// that does not type-check expressions fully.
+func (d *contextSchemaDefinition) checkExpr(expr string, fields ...string) []string {
+	var (
+		tokens   = tokenizeExpr(expr)
+		problems []string
+		reported = map[string]bool{}
+		locals   = map[string]bool{}
+	)
+	for _, f := range fields {
+		locals[f] = true
+	}
+	at := func(i int) exprToken {
+		if i < 0 || i >= len(tokens) {
+			return exprToken{tokenOperator, ""}
+		}
+		return tokens[i]
+	}
+	report := func(format string, args ...any) {
+		problem := fmt.Sprintf(format, args...)
+		if !reported[problem] {
+			reported[problem] = true
+			problems = append(problems, problem)
+		}
+	}
+	// attribute returns the type of the attribute at i, if it is used as a
+	// whole rather than through one of its fields or methods.
+	attribute := func(i int) (string, bool) {
+		if at(i).kind != tokenIdent || locals[at(i).text] || at(i-1).text == "." || at(i+1).text == "." || at(i+1).text == "(" {
+			return "", false
+		}
+		t, ok := d.Attributes[at(i).text]
+		return t, ok
+	}
+	// literal returns the type of the literal at i, skipping a unary minus.
+	literal := func(i int) (string, bool) {
+		if at(i).text == "-" {
+			i++
+		}
+		t, ok := literalTypes[at(i).kind]
+		return t, ok
+	}
+	compare := func(name, typ, lit string) {
+		if !contextTypeAccepts(typ, lit) {
+			report("%s is %s %s, but is compared with %s %s", name, article(typ), typ, article(lit), lit)
+		}
+	}
+
+	for i, tok := range tokens {
+		if tok.kind == tokenIdent && at(i-1).text == "." && slices.Contains(exprMacros, tok.text) &&
+			at(i+1).text == "(" && at(i+2).kind == tokenIdent && at(i+3).text == "," {
+			locals[at(i+2).text] = true
+		}
+	}
+
+	for i, tok := range tokens {
+		switch {
+		case tok.kind == tokenIdent && at(i-1).text != "." && at(i+1).text != "(" && tok.text != "in" && !locals[tok.text]:
+			typ, ok := d.Attributes[tok.text]
+			if !ok {
+				report("%s is not an attribute of context schema %q", tok.text, d.Key)
+				continue
+			}
+			if at(i+1).text == "." && at(i+3).text == "(" && slices.Contains(stringMethods, at(i+2).text) && typ != contextTypeString {
+				report("%s is %s %s, but %s() is a method of strings", tok.text, article(typ), typ, at(i+2).text)
+			}
+
+		case slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, tok.text):
+			if typ, ok := attribute(i - 1); ok {
+				if lit, ok := literal(i + 1); ok {
+					compare(at(i-1).text, typ, lit)
+				}
+			}
+			if typ, ok := attribute(i + 1); ok {
+				if lit, ok := literal(i - 1); ok {
+					compare(at(i+1).text, typ, lit)
+				}
+			}
+
+		case tok.text == "in":
+			typ, ok := attribute(i - 1)
+			if !ok || at(i+1).text != "[" {
+				continue
+			}
+			// Compare the elements of a list literal, as in env in ['dev', 'test'].
+			depth := 0
+			for j := i + 1; j < len(tokens); j++ {
+				switch tokens[j].text {
+				case "[", "(", "{":
+					depth++
+				case "]", ")", "}":
+					depth--
+				}
+				if depth == 0 {
+					break
+				}
+				if lit, ok := literal(j); ok && depth == 1 && (at(j-1).text == "[" || at(j-1).text == ",") {
+					compare(at(i-1).text, typ, lit)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// checkVariables returns the problems with the variables of a test, which
+// must be JSON that assigns values of the declared types to attributes of the
+// schema.
+func (d *contextSchemaDefinition) checkVariables(variables string) []string {
+	var vars map[string]any
+	if err := json.Unmarshal([]byte(variables), &vars); err != nil {
+		return nil
+	}
+
+	var problems []string
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		typ, ok := d.Attributes[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not an attribute of context schema %q", name, d.Key))
+			continue
+		}
+		if got := jsonContextType(vars[name]); got != "" && !contextTypeAccepts(typ, got) &&
+			(typ != contextTypeTimestamp || !isTimestamp(vars[name])) {
+			problems = append(problems, fmt.Sprintf("%s is %s %s, but is set to %s %s", name, article(typ), typ, article(got), got))
+		}
+	}
+	return problems
+}
+
+// jsonContextType returns the context type of the JSON value v, or "" for
+// null.
+func jsonContextType(v any) string {
+	switch v := v.(type) {
+	case string:
+		return contextTypeString
+	case float64:
+		if v == math.Trunc(v) {
+			return contextTypeInt
+		}
+		return contextTypeDouble
+	case bool:
+		return contextTypeBool
+	case []any:
+		return contextTypeList
+	case map[string]any:
+		return "object"
+	}
+	return ""
+}
+
+func isTimestamp(v any) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
+
+// contextTypeAccepts reports whether an attribute of type typ can be compared
+// with or set to a value of type got. Doubles also accept integers.
+func contextTypeAccepts(typ, got string) bool {
+	return typ == got || typ == contextTypeDouble && got == contextTypeInt
+}
+
+func article(typ string) string {
+	if typ != "" && strings.ContainsRune("aeiou", rune(typ[0])) {
+		return "an"
+	}
+	return "a"
+}
@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+func testContextSchemaDefinition() *contextSchemaDefinition {
+	return &contextSchemaDefinition{
+		Key: "default",
+		Attributes: map[string]string{
+			"env":       contextTypeString,
+			"userId":    contextTypeString,
+			"count":     contextTypeInt,
+			"ratio":     contextTypeDouble,
+			"beta":      contextTypeBool,
+			"groups":    contextTypeList,
+			"createdAt": contextTypeTimestamp,
+		},
+	}
+}
+
+func Test_ContextSchemaCheckExpr(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{expr: "env == 'dev' && count > 1"},
+		{expr: "ratio >= 0.5 || ratio < 1 || count == -1"},
+		{expr: "'admin' in groups && env in ['dev', 'test']"},
+		{expr: "groups.exists(g, g.startsWith('team-')) && userId.endsWith('@example.com')"},
+		{expr: "createdAt > timestamp('2024-01-01T00:00:00Z') && beta"},
+		{expr: "size(env) > 0 && has(env) && env != null"},
+		{
+			expr: "count == '1'",
+			want: []string{"count is an int, but is compared with a string"},
+		},
+		{
+			expr: "'dev' == env || 1.5 == count",
+			want: []string{"count is an int, but is compared with a double"},
+		},
+		{
+			expr: "env in ['dev', 1] && beta == 'true'",
+			want: []string{"env is a string, but is compared with an int", "beta is a bool, but is compared with a string"},
+		},
+		{
+			expr: "country == 'NL' && country != 'BE'",
+			want: []string{`country is not an attribute of context schema "default"`},
+		},
+		{
+			expr: "count.startsWith('1')",
+			want: []string{"count is an int, but startsWith() is a method of strings"},
+		},
+		{
+			expr: "createdAt > '2024-01-01'",
+			want: []string{"createdAt is a timestamp, but is compared with a string"},
+		},
+	}
+	d := testContextSchemaDefinition()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := d.checkExpr(tt.expr); !slices.Equal(got, tt.want) {
+				t.Errorf("checkExpr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ContextSchemaCheckVariables(t *testing.T) {
+	tests := []struct {
+		variables string
+		want      []string
+	}{
+		{variables: `{"env": "test", "count": 1, "ratio": 1, "beta": false, "groups": ["a"], "createdAt": "2024-03-13T10:19:33Z"}`},
+		{variables: `{"env": null}`},
+		{
+			variables: `{"env": "test", "count": "1"}`,
+			want:      []string{"count is an int, but is set to a string"},
+		},
+		{
+			variables: `{"count": 1.5, "createdAt": "yesterday", "region": "eu"}`,
+			want: []string{
+				"count is an int, but is set to a double",
+				"createdAt is a timestamp, but is set to a string",
+				`region is not an attribute of context schema "default"`,
+			},
+		},
+	}
+	d := testContextSchemaDefinition()
+	for _, tt := range tests {
+		t.Run(tt.variables, func(t *testing.T) {
+			if got := d.checkVariables(tt.variables); !slices.Equal(got, tt.want) {
+				t.Errorf("checkVariables() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ParseContextSchemaDefinition(t *testing.T) {
+	d, err := parseContextSchemaDefinition(`{"key":"default","attributes":{"count":"int"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Attributes["count"] != contextTypeInt {
+		t.Errorf("attributes = %v, want count of type int", d.Attributes)
+	}
+
+	for _, definition := range []string{
+		`{"key":"k","attributes":{"count":""}}`,
+		`{"key":"k","attributes":{"count":"integer"}}`,
+		`not json`,
+	} {
+		if _, err := parseContextSchemaDefinition(definition); err == nil {
+			t.Errorf("parseContextSchemaDefinition(%s) succeeded, want an error", definition)
+		}
+	}
+}
+
+func Test_ContextSchemaCheckTransform(t *testing.T) {
+	var value model.Value
+	if err := json.Unmarshal([]byte(objectTestdata), &value); err != nil {
+		t.Fatal(err)
+	}
+	object := value.Variants["json"].Object
+	fields := slices.Collect(maps.Keys(object.Value))
+
+	d := testContextSchemaDefinition()
+	for _, transform := range object.Transforms {
+		if got := d.checkExpr(transform.Expr, fields...); len(got) != 0 {
+			t.Errorf("checkExpr(%s) = %q, want no problems", transform.Expr, got)
+		}
+	}
+
+	// Fields take precedence over context attributes of the same name.
+	if got := d.checkExpr("count == 'many'", "count"); len(got) != 0 {
+		t.Errorf("checkExpr() = %q, want no problems", got)
+	}
+	want := []string{`items is not an attribute of context schema "default"`}
+	if got := d.checkExpr(object.Transforms[0].Expr); !slices.Equal(got, want) {
+		t.Errorf("checkExpr() without fields = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// processCredentials is the JSON document a credential process prints to stdout.
+type processCredentials struct {
+	APIKeyID  string     `json:"api_key_id"`
+	APIKey    string     `json:"api_key"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// credentialProcess obtains API keys by running an external command, similar
+// to credential_process in the AWS CLI. The credentials are cached until
+// shortly before they expire; credentials without an expiry are cached for
+// the lifetime of the provider.
+type credentialProcess struct {
+	command string
+	now     func() time.Time
+
+	mu     sync.Mutex
+	cached *processCredentials
+}
+
+func newCredentialProcess(command string) *credentialProcess {
+	return &credentialProcess{
+		command: command,
+		now:     time.Now,
+	}
+}
+
+func (p *credentialProcess) apiKey(ctx context.Context) (string, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cached == nil || p.expired(p.cached) {
+		creds, err := p.run(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		p.cached = creds
+	}
+	return p.cached.APIKeyID, p.cached.APIKey, nil
+}
+
+func (p *credentialProcess) expired(creds *processCredentials) bool {
+	return creds.ExpiresAt != nil && !p.now().Add(credentialRefreshWindow).Before(*creds.ExpiresAt)
+}
+
+func (p *credentialProcess) run(ctx context.Context) (*processCredentials, error) {
+	args, err := splitCommand(p.command)
+	if err != nil {
+		return nil, fmt.Errorf("credential_process: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential_process %q failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	creds := new(processCredentials)
+	if err := json.Unmarshal(stdout.Bytes(), creds); err != nil {
+		return nil, fmt.Errorf("credential_process %q printed invalid JSON: %w", args[0], err)
+	}
+	if creds.APIKeyID == "" || creds.APIKey == "" {
+		return nil, fmt.Errorf("credential_process %q did not print both api_key_id and api_key", args[0])
+	}
+	if p.expired(creds) {
+		return nil, fmt.Errorf("credential_process %q printed credentials that expire at %s", args[0], creds.ExpiresAt.Format(time.RFC3339))
+	}
+	return creds, nil
+}
+
+// splitCommand splits a command line into arguments the way a POSIX shell
+// would, honoring single quotes, double quotes and backslash escapes. No other
+// shell features are supported.
+func splitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, c := range command {
+		switch {
+		case escaped:
+			// Within double quotes, a backslash only escapes the characters
+			// that are special there, and is kept before any other.
+			if quote == '"' && !strings.ContainsRune("\"\\$`", c) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inArg = c, true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, errors.New("unterminated quote or escape in command")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return args, nil
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCredentialProcessHelper is not a real test. It is run as the credential
+// process by the tests below, printing the JSON after "--" and recording each
+// invocation in the file that follows it.
+func TestCredentialProcessHelper(t *testing.T) {
+	i := slices.Index(os.Args, "--")
+	if i < 0 || len(os.Args) < i+3 {
+		return
+	}
+	f, err := os.OpenFile(os.Args[i+1], os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		os.Exit(2)
+	}
+	fmt.Fprintln(f, "invoked")
+	f.Close()
+
+	if os.Args[i+2] == "fail" {
+		fmt.Fprint(os.Stderr, "vault is sealed")
+		os.Exit(1)
+	}
+	fmt.Print(os.Args[i+2])
+	os.Exit(0)
+}
+
+func testCredentialProcess(t *testing.T, output string) (*credentialProcess, func() int) {
+	t.Helper()
+
+	log := filepath.Join(t.TempDir(), "invocations")
+	command := fmt.Sprintf("'%s' -test.run=^TestCredentialProcessHelper$ -- '%s' '%s'", os.Args[0], log, output)
+	invocations := func() int {
+		b, err := os.ReadFile(log)
+		if err != nil {
+			return 0
+		}
+		return strings.Count(string(b), "invoked")
+	}
+	return newCredentialProcess(command), invocations
+}
+
+func Test_CredentialProcess(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 13, 10, 0, 0, 0, time.UTC)
+	p, invocations := testCredentialProcess(t, `{"api_key_id":"id","api_key":"key","expires_at":"2024-03-13T11:00:00Z"}`)
+	p.now = func() time.Time { return now }
+
+	for range 2 {
+		keyID, key, err := p.apiKey(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keyID != "id" || key != "key" {
+			t.Errorf("apiKey() = %q, %q, want id, key", keyID, key)
+		}
+	}
+	if n := invocations(); n != 1 {
+		t.Errorf("credential process ran %d times, want the result to be cached", n)
+	}
+
+	// Close to expiry the process is run again. It keeps returning the same
+	// expiry, so from here on the credentials count as expired.
+	now = now.Add(time.Hour - credentialRefreshWindow/2)
+	if _, _, err := p.apiKey(context.Background()); err == nil || !strings.Contains(err.Error(), "expire at") {
+		t.Errorf("error = %v, want expired credentials to be rejected", err)
+	}
+	if n := invocations(); n != 2 {
+		t.Errorf("credential process ran %d times, want 2", n)
+	}
+}
+
+func Test_CredentialProcess_NoExpiry(t *testing.T) {
+	t.Parallel()
+
+	p, invocations := testCredentialProcess(t, `{"api_key_id":"id","api_key":"key"}`)
+	p.now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+
+	for range 3 {
+		if _, _, err := p.apiKey(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := invocations(); n != 1 {
+		t.Errorf("credential process ran %d times, want 1", n)
+	}
+}
+
+func Test_CredentialProcess_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"fail":                  "vault is sealed",
+		"not json":              "invalid JSON",
+		`{"api_key_id":"id"}`:   "did not print both",
+		`{"api_key":"key"}`:     "did not print both",
+		`{"api_key_id":"id",x}`: "invalid JSON",
+	}
+	for output, want := range tests {
+		p, _ := testCredentialProcess(t, output)
+		_, _, err := p.apiKey(context.Background())
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("output %q: error = %v, want %q", output, err, want)
+		}
+	}
+}
+
+func Test_SplitCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{command: "vault-creds", want: []string{"vault-creds"}},
+		{command: "  vault-creds  --role  wings ", want: []string{"vault-creds", "--role", "wings"}},
+		{command: `"/opt/my tools/creds" 'a b' c\ d ""`, want: []string{"/opt/my tools/creds", "a b", "c d", ""}},
+		{command: `creds '{"role":"wings"}'`, want: []string{"creds", `{"role":"wings"}`}},
+		{command: `creds "it's"`, want: []string{"creds", "it's"}},
+		{command: `"C:\tools\cred.exe" --role wings`, want: []string{`C:\tools\cred.exe`, "--role", "wings"}},
+		{command: `creds "a \"b\" \\ \$HOME \` + "`" + `x"`, want: []string{"creds", `a "b" \ $HOME ` + "`" + `x`}},
+		{command: `creds C:\tools\cred.exe`, want: []string{"creds", `C:toolscred.exe`}},
+		{command: "", wantErr: true},
+		{command: "creds 'unterminated", wantErr: true},
+		{command: `creds trailing\`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := splitCommand(tt.command)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitCommand(%q) = %q, want error", tt.command, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitCommand(%q) unexpected error: %v", tt.command, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	envEndpoint              = "WINGS_ENDPOINT"
+	envAPIKeyID              = "WINGS_API_KEY_ID"
+	envAPIKey                = "WINGS_API_KEY"
+	envProfile               = "WINGS_PROFILE"
+	envSharedCredentialsFile = "WINGS_SHARED_CREDENTIALS_FILE"
+	envCredentialProcess     = "WINGS_CREDENTIAL_PROCESS"
+	envProject               = "WINGS_PROJECT"
+	envEnvironment           = "WINGS_ENVIRONMENT"
+)
+
+const defaultProfile = "default"
+
+// credentials are the settings needed to reach and authenticate to Wings.
+type credentials struct {
+	Endpoint    string
+	APIKeyID    string
+	APIKey      string
+	BearerToken string
+
+	CredentialProcess string
+
+	// sources records where the authentication settings were resolved from,
+	// so that the authentication method is picked from the one with the
+	// highest precedence.
+	sources credentialSources
+}
+
+// credentialSource is where a setting was resolved from. Sources with higher
+// precedence compare greater.
+type credentialSource int
+
+const (
+	sourceNone credentialSource = iota
+	sourceFile
+	sourceEnv
+	sourceConfig
+)
+
+type credentialSources struct {
+	APIKeyID          credentialSource
+	APIKey            credentialSource
+	BearerToken       credentialSource
+	CredentialProcess credentialSource
+}
+
+// resolveCredentials resolves each setting from, in order of precedence, the
+// provider configuration, the environment and the profile in the shared
+// credentials file.
+func resolveCredentials(cfg *wingsProviderModel, getenv func(string) string) (*credentials, error) {
+	profile, explicitProfile := firstNonEmpty(cfg.Profile.ValueString(), getenv(envProfile)), true
+	if profile == "" {
+		profile, explicitProfile = defaultProfile, false
+	}
+
+	file, explicitFile := firstNonEmpty(cfg.SharedCredentialsFile.ValueString(), getenv(envSharedCredentialsFile)), true
+	if file == "" {
+		file, explicitFile = defaultSharedCredentialsFile(getenv), false
+	}
+
+	creds := &credentials{}
+	if file != "" {
+		fromFile, err := loadSharedCredentials(file, profile)
+		switch {
+		case err == nil:
+			creds = fromFile
+		case errors.Is(err, fs.ErrNotExist) && !explicitFile && !explicitProfile:
+			// Without a shared credentials file, configuration and environment are all there is.
+		default:
+			return nil, err
+		}
+	}
+
+	creds.Endpoint = firstNonEmpty(cfg.Endpoint.ValueString(), getenv(envEndpoint), creds.Endpoint)
+	creds.APIKeyID, creds.sources.APIKeyID = resolveSetting(cfg.APIKeyID.ValueString(), getenv(envAPIKeyID), creds.APIKeyID)
+	creds.APIKey, creds.sources.APIKey = resolveSetting(cfg.APIKey.ValueString(), getenv(envAPIKey), creds.APIKey)
+	creds.BearerToken, creds.sources.BearerToken = resolveSetting(cfg.BearerToken.ValueString(), getenv(envBearerToken), creds.BearerToken)
+	creds.CredentialProcess, creds.sources.CredentialProcess = resolveSetting(
+		cfg.CredentialProcess.ValueString(), getenv(envCredentialProcess), creds.CredentialProcess)
+	return creds, nil
+}
+
+// resolveSetting returns the first of the configured, environment and file
+// values that is set, and where it came from.
+func resolveSetting(configured, env, file string) (string, credentialSource) {
+	switch {
+	case configured != "":
+		return configured, sourceConfig
+	case env != "":
+		return env, sourceEnv
+	case file != "":
+		return file, sourceFile
+	}
+	return "", sourceNone
+}
+
+func defaultSharedCredentialsFile(getenv func(string) string) string {
+	home := getenv("HOME")
+	if home == "" {
+		home, _ = os.UserHomeDir()
+	}
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".wings", "credentials")
+}
+
+// loadSharedCredentials reads profile from the shared credentials file at path.
+func loadSharedCredentials(path, profile string) (*credentials, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading shared credentials file: %w", err)
+	}
+	defer f.Close()
+
+	profiles, err := parseSharedCredentials(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing shared credentials file %s: %w", path, err)
+	}
+
+	creds, ok := profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in shared credentials file %s: %w", profile, path, fs.ErrNotExist)
+	}
+	return creds, nil
+}
+
+// parseSharedCredentials parses an INI style credentials file:
+//
+//	[default]
+//	endpoint   = https://wings.example.com
+//	api_key_id = ...
+//	api_key    = ...
+//
+// Lines starting with '#' or ';' are comments.
+func parseSharedCredentials(r io.Reader) (map[string]*credentials, error) {
+	var (
+		profiles = make(map[string]*credentials)
+		current  *credentials
+		line     int
+	)
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line++
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: unterminated profile header", line)
+			}
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty profile name", line)
+			}
+			current = &credentials{}
+			profiles[name] = current
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: setting outside of a profile", line)
+		}
+
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "endpoint":
+			current.Endpoint = value
+		case "api_key_id":
+			current.APIKeyID = value
+		case "api_key":
+			current.APIKey = value
+		case "bearer_token":
+			current.BearerToken = value
+		case "credential_process":
+			current.CredentialProcess = value
+		default:
+			// Unknown settings are ignored so the file can be shared with other tools.
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const testSharedCredentials = `
+# Wings credentials
+[default]
+endpoint   = https://file.example.com
+api_key_id = file_key_id
+api_key    = file_key
+
+[staging]
+endpoint = https://staging.example.com
+api_key_id=staging_key_id
+; api_key is left to the environment
+region = ignored
+`
+
+func writeSharedCredentials(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testEnv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func nullProviderModel() *wingsProviderModel {
+	return &wingsProviderModel{
+		Endpoint:              types.StringNull(),
+		APIKeyID:              types.StringNull(),
+		APIKey:                types.StringNull(),
+		Profile:               types.StringNull(),
+		SharedCredentialsFile: types.StringNull(),
+	}
+}
+
+func Test_ResolveCredentials(t *testing.T) {
+	t.Parallel()
+
+	file := writeSharedCredentials(t, testSharedCredentials)
+	home := t.TempDir()
+	homeWithFile := t.TempDir()
+	if err := os.Mkdir(filepath.Join(homeWithFile, ".wings"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeWithFile, ".wings", "credentials"), []byte(testSharedCredentials), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		cfg    func(*wingsProviderModel)
+		env    map[string]string
+		want   credentials
+		errMsg string
+	}{
+		{
+			name: "shared credentials file",
+			env:  map[string]string{envSharedCredentialsFile: file},
+			want: credentials{Endpoint: "https://file.example.com", APIKeyID: "file_key_id", APIKey: "file_key"},
+		},
+		{
+			name: "environment overrides file",
+			env: map[string]string{
+				envSharedCredentialsFile: file,
+				envAPIKey:                "env_key",
+			},
+			want: credentials{Endpoint: "https://file.example.com", APIKeyID: "file_key_id", APIKey: "env_key"},
+		},
+		{
+			name: "configuration overrides environment",
+			cfg: func(m *wingsProviderModel) {
+				m.APIKey = types.StringValue("config_key")
+				m.SharedCredentialsFile = types.StringValue(file)
+			},
+			env: map[string]string{
+				envEndpoint: "https://env.example.com",
+				envAPIKey:   "env_key",
+			},
+			want: credentials{Endpoint: "https://env.example.com", APIKeyID: "file_key_id", APIKey: "config_key"},
+		},
+		{
+			name: "profile from environment",
+			env: map[string]string{
+				envSharedCredentialsFile: file,
+				envProfile:               "staging",
+				envAPIKey:                "env_key",
+			},
+			want: credentials{Endpoint: "https://staging.example.com", APIKeyID: "staging_key_id", APIKey: "env_key"},
+		},
+		{
+			name: "profile from configuration overrides environment",
+			cfg: func(m *wingsProviderModel) {
+				m.Profile = types.StringValue("default")
+				m.SharedCredentialsFile = types.StringValue(file)
+			},
+			env:  map[string]string{envProfile: "staging"},
+			want: credentials{Endpoint: "https://file.example.com", APIKeyID: "file_key_id", APIKey: "file_key"},
+		},
+		{
+			name: "missing default file is ignored",
+			env: map[string]string{
+				envEndpoint: "https://env.example.com",
+				envAPIKeyID: "env_key_id",
+				envAPIKey:   "env_key",
+			},
+			want: credentials{Endpoint: "https://env.example.com", APIKeyID: "env_key_id", APIKey: "env_key"},
+		},
+		{
+			name: "default file",
+			cfg: func(m *wingsProviderModel) {
+				m.Profile = types.StringValue("staging")
+			},
+			env: map[string]string{
+				"HOME":    homeWithFile,
+				envAPIKey: "env_key",
+			},
+			want: credentials{Endpoint: "https://staging.example.com", APIKeyID: "staging_key_id", APIKey: "env_key"},
+		},
+		{
+			name:   "missing explicit file",
+			env:    map[string]string{envSharedCredentialsFile: filepath.Join(t.TempDir(), "missing")},
+			errMsg: "reading shared credentials file",
+		},
+		{
+			name: "missing explicit profile",
+			cfg: func(m *wingsProviderModel) {
+				m.Profile = types.StringValue("production")
+			},
+			env:    map[string]string{envSharedCredentialsFile: file},
+			errMsg: `profile "production" not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := nullProviderModel()
+			if tt.cfg != nil {
+				tt.cfg(cfg)
+			}
+			// Keep the developer's real ~/.wings/credentials out of the test.
+			env := map[string]string{"HOME": home}
+			for k, v := range tt.env {
+				env[k] = v
+			}
+
+			got, err := resolveCredentials(cfg, testEnv(env))
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("error = %v, want %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Where the settings came from is checked by Test_SelectAuthMethod_Precedence.
+			got.sources = credentialSources{}
+			if *got != tt.want {
+				t.Errorf("credentials = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ParseSharedCredentials(t *testing.T) {
+	t.Parallel()
+
+	profiles, err := parseSharedCredentials(strings.NewReader(testSharedCredentials))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("profiles = %d, want 2", len(profiles))
+	}
+	if got := profiles["staging"].APIKey; got != "" {
+		t.Errorf("staging api_key = %q, want empty", got)
+	}
+
+	for _, invalid := range []string{
+		"api_key = outside",
+		"[default\napi_key = x",
+		"[]",
+		"[default]\napi_key",
+	} {
+		if _, err := parseSharedCredentials(strings.NewReader(invalid)); err == nil {
+			t.Errorf("parseSharedCredentials(%q) succeeded, want error", invalid)
+		}
+	}
+}
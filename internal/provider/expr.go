@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// The kinds of the tokens of an expression.
+const (
+	tokenIdent = iota
+	tokenString
+	tokenInt
+	tokenDouble
+	tokenBool
+	tokenNull
+	tokenOperator
+)
+
+type exprToken struct {
+	kind int
+	text string
+}
+
+// tokenizeExpr splits expr into identifiers, literals and operators. It only
+// needs to be good enough to find the context attributes an expression uses
+// and the literals they are compared with, and to compare expressions
+// regardless of formatting, so it accepts invalid expressions.
+func tokenizeExpr(expr string) []exprToken {
+	var (
+		tokens []exprToken
+		rs     = []rune(expr)
+	)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			j := i + 1
+			for ; j < len(rs) && rs[j] != c; j++ {
+				if rs[j] == '\\' {
+					j++
+				}
+			}
+			j = min(j+1, len(rs))
+			tokens = append(tokens, exprToken{tokenString, string(rs[i:j])})
+			i = j
+		case unicode.IsDigit(c):
+			j, kind := i, tokenInt
+			for j < len(rs) && (isIdentRune(rs[j]) || rs[j] == '.' && kind == tokenInt) {
+				if rs[j] == '.' {
+					kind = tokenDouble
+				}
+				j++
+			}
+			if kind == tokenInt && strings.ContainsAny(string(rs[i:j]), "eE") && !strings.HasPrefix(string(rs[i:j]), "0x") {
+				kind = tokenDouble
+			}
+			tokens = append(tokens, exprToken{kind, string(rs[i:j])})
+			i = j
+		case isIdentRune(c):
+			j := i
+			for j < len(rs) && isIdentRune(rs[j]) {
+				j++
+			}
+			text, kind := string(rs[i:j]), tokenIdent
+			switch text {
+			case "true", "false":
+				kind = tokenBool
+			case "null":
+				kind = tokenNull
+			}
+			tokens = append(tokens, exprToken{kind, text})
+			i = j
+		default:
+			j := i + 1
+			if j < len(rs) && slices.Contains([]string{"==", "!=", "<=", ">=", "&&", "||"}, string(rs[i:j+1])) {
+				j++
+			}
+			tokens = append(tokens, exprToken{tokenOperator, string(rs[i:j])})
+			i = j
+		}
+	}
+	return tokens
+}
+
+func isIdentRune(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
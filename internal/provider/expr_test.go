@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_TokenizeExpr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expr string
+		want []exprToken
+	}{
+		{
+			expr: "env=='dev' && age >= 18",
+			want: []exprToken{
+				{tokenIdent, "env"}, {tokenOperator, "=="}, {tokenString, "'dev'"}, {tokenOperator, "&&"},
+				{tokenIdent, "age"}, {tokenOperator, ">="}, {tokenInt, "18"},
+			},
+		},
+		{
+			expr: `name == "a \" b" || ratio < 0.5`,
+			want: []exprToken{
+				{tokenIdent, "name"}, {tokenOperator, "=="}, {tokenString, `"a \" b"`}, {tokenOperator, "||"},
+				{tokenIdent, "ratio"}, {tokenOperator, "<"}, {tokenDouble, "0.5"},
+			},
+		},
+		{
+			expr: "beta != null && enabled == true",
+			want: []exprToken{
+				{tokenIdent, "beta"}, {tokenOperator, "!="}, {tokenNull, "null"}, {tokenOperator, "&&"},
+				{tokenIdent, "enabled"}, {tokenOperator, "=="}, {tokenBool, "true"},
+			},
+		},
+		{
+			// Invalid expressions are tokenized as far as possible.
+			expr: "country in ['NL'",
+			want: []exprToken{{tokenIdent, "country"}, {tokenIdent, "in"}, {tokenOperator, "["}, {tokenString, "'NL'"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := tokenizeExpr(tt.expr); !slices.Equal(got, tt.want) {
+			t.Errorf("tokenizeExpr(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
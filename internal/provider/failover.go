@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// endpointCooldown is how long an endpoint that failed is only tried after
+// the endpoints that did not.
+const endpointCooldown = 30 * time.Second
+
+// failoverTransport sends requests for the primary endpoint to the first
+// healthy one of several endpoints serving the same API. Requests are built
+// against the primary endpoint and rebased onto the others, so callers are
+// unaware of the failover.
+//
+// An endpoint that fails with a connection error or a 5xx status is moved
+// behind the others for endpointCooldown, and the request is sent to the next
+// endpoint. The last endpoint's response or error is returned as is, so that
+// the retrying client above can retry the whole round.
+type failoverTransport struct {
+	base      http.RoundTripper
+	endpoints []*url.URL
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failedAt []time.Time
+}
+
+func newFailoverTransport(base http.RoundTripper, endpoints []string) (*failoverTransport, error) {
+	t := &failoverTransport{
+		base:     base,
+		now:      time.Now,
+		failedAt: make([]time.Time, len(endpoints)),
+	}
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("endpoint %q must be an http or https URL to be used with fallback endpoints", endpoint)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = ""
+		t.endpoints = append(t.endpoints, u)
+	}
+	return t, nil
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rel, ok := t.relativePath(req.URL)
+	if !ok {
+		// Such as OAuth2 token requests, which are not sent to Wings.
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	order := t.order()
+	for i, idx := range order {
+		endpoint := t.endpoints[idx]
+		attempt, err := rebaseRequest(req, endpoint, rel, i > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(attempt)
+		last := i == len(order)-1
+		fields := map[string]any{
+			"wings_endpoint": endpoint.String(),
+			"method":         req.Method,
+			"path":           rel,
+		}
+		switch {
+		case err != nil:
+			t.setFailed(idx, true)
+			if last {
+				return nil, err
+			}
+			fields["error"] = err.Error()
+			tflog.Warn(ctx, "Wings endpoint unreachable, failing over", fields)
+		case resp.StatusCode >= http.StatusInternalServerError:
+			t.setFailed(idx, true)
+			if last {
+				fields["status_code"] = resp.StatusCode
+				tflog.Debug(ctx, "Wings request served", fields)
+				return resp, nil
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			fields["status_code"] = resp.StatusCode
+			tflog.Warn(ctx, "Wings endpoint failed, failing over", fields)
+		default:
+			t.setFailed(idx, false)
+			fields["status_code"] = resp.StatusCode
+			tflog.Debug(ctx, "Wings request served", fields)
+			return resp, nil
+		}
+	}
+	return nil, errors.New("no Wings endpoint to send the request to")
+}
+
+// relativePath returns the path of u below the primary endpoint, or false if
+// u is not a Wings API URL.
+func (t *failoverTransport) relativePath(u *url.URL) (string, bool) {
+	primary := t.endpoints[0]
+	if u.Scheme != primary.Scheme || u.Host != primary.Host {
+		return "", false
+	}
+	rel, ok := strings.CutPrefix(u.Path, primary.Path)
+	if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+		return "", false
+	}
+	return rel, true
+}
+
+// samePaths reports whether all endpoints serve the API at the same path.
+func (t *failoverTransport) samePaths() bool {
+	for _, u := range t.endpoints[1:] {
+		if u.Path != t.endpoints[0].Path {
+			return false
+		}
+	}
+	return true
+}
+
+// order returns the indexes of the endpoints in the order to try them: those
+// that have not failed recently in configuration order, then the others from
+// the least recently failed.
+func (t *failoverTransport) order() []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var healthy, cooling []int
+	for i, failedAt := range t.failedAt {
+		if failedAt.IsZero() || now.Sub(failedAt) >= endpointCooldown {
+			healthy = append(healthy, i)
+		} else {
+			cooling = append(cooling, i)
+		}
+	}
+	slices.SortStableFunc(cooling, func(a, b int) int {
+		return t.failedAt[a].Compare(t.failedAt[b])
+	})
+	return append(healthy, cooling...)
+}
+
+func (t *failoverTransport) setFailed(idx int, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if failed {
+		t.failedAt[idx] = t.now()
+	} else {
+		t.failedAt[idx] = time.Time{}
+	}
+}
+
+// rebaseRequest returns a copy of req sent to endpoint. The body of a request
+// that is sent again is replayed with GetBody.
+func rebaseRequest(req *http.Request, endpoint *url.URL, rel string, resend bool) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	u := *req.URL
+	u.Scheme = endpoint.Scheme
+	u.Host = endpoint.Host
+	u.Path = endpoint.Path + rel
+	u.RawPath = ""
+	r.URL = &u
+	r.Host = ""
+
+	if resend && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("cannot fail over %s %s: the request body cannot be replayed", req.Method, rel)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// testRegion is a Wings endpoint whose health can be switched, recording the
+// requests it received.
+type testRegion struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []string
+}
+
+func newTestRegion(t *testing.T, prefix string) *testRegion {
+	t.Helper()
+
+	r := &testRegion{status: http.StatusOK}
+	mux := http.NewServeMux()
+	mux.HandleFunc(prefix+"/values/", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req.Method+" "+req.URL.Path+" "+string(body))
+		status := r.status
+		r.mu.Unlock()
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprint(w, `{"id":"checkout","enabled":true}`)
+	})
+	r.srv = httptest.NewServer(mux)
+	t.Cleanup(r.srv.Close)
+	return r
+}
+
+func (r *testRegion) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *testRegion) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	received := r.requests
+	r.requests = nil
+	return received
+}
+
+func Test_FailoverTransport(t *testing.T) {
+	t.Parallel()
+
+	primary := newTestRegion(t, "/api")
+	secondary := newTestRegion(t, "/wings/api")
+	failover, err := newFailoverTransport(http.DefaultTransport, []string{primary.srv.URL + "/api", secondary.srv.URL + "/wings/api/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	failover.now = func() time.Time { return now }
+	c := &config{endpoint: primary.srv.URL + "/api", client: &http.Client{Transport: failover}}
+
+	if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err != nil {
+		t.Fatal(err)
+	}
+	if got := primary.received(); !slices.Equal(got, []string{"GET /api/values/checkout "}) {
+		t.Errorf("primary received %q", got)
+	}
+
+	// A failing primary is skipped, replaying the body on the secondary.
+	primary.setStatus(http.StatusServiceUnavailable)
+	if _, err := c.UpdateValue(context.Background(), &model.Value{ID: "checkout", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := primary.received(); len(got) != 1 {
+		t.Errorf("primary received %q, want the failed attempt", got)
+	}
+	if got := secondary.received(); len(got) != 1 || !strings.HasPrefix(got[0], `PUT /wings/api/values/checkout {"id":"checkout","enabled":true`) {
+		t.Errorf("secondary received %q", got)
+	}
+
+	// Within the cooldown the primary is not tried first, even once it recovered.
+	primary.setStatus(http.StatusOK)
+	if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err != nil {
+		t.Fatal(err)
+	}
+	if got := primary.received(); len(got) != 0 {
+		t.Errorf("primary received %q during its cooldown", got)
+	}
+	if got := secondary.received(); len(got) != 1 {
+		t.Errorf("secondary received %q, want 1 request", got)
+	}
+
+	now = now.Add(endpointCooldown)
+	if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err != nil {
+		t.Fatal(err)
+	}
+	if got := primary.received(); len(got) != 1 {
+		t.Errorf("primary received %q after its cooldown, want 1 request", got)
+	}
+}
+
+func Test_FailoverTransport_AllFailing(t *testing.T) {
+	t.Parallel()
+
+	primary := newTestRegion(t, "")
+	secondary := newTestRegion(t, "")
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	failover, err := newFailoverTransport(http.DefaultTransport, []string{unreachable.URL, primary.srv.URL, secondary.srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary.setStatus(http.StatusBadGateway)
+	secondary.setStatus(http.StatusInternalServerError)
+
+	c := &config{endpoint: unreachable.URL, client: &http.Client{Transport: failover}}
+	_, err = c.GetValue(context.Background(), model.Scope{}, "checkout")
+	if !hasStatusCode(err, http.StatusInternalServerError) {
+		t.Errorf("error = %v, want the last endpoint's status", err)
+	}
+	if len(primary.received()) != 1 || len(secondary.received()) != 1 {
+		t.Error("not every endpoint was tried")
+	}
+}
+
+func Test_FailoverTransport_OtherHosts(t *testing.T) {
+	t.Parallel()
+
+	primary := newTestRegion(t, "")
+	other := newTestRegion(t, "")
+	failover, err := newFailoverTransport(http.DefaultTransport, []string{primary.srv.URL, other.srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := (&http.Client{Transport: failover}).Get(other.srv.URL + "/values/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(primary.received()) != 0 || len(other.received()) != 1 {
+		t.Error("a request to another host was rebased")
+	}
+
+	for _, endpoints := range [][]string{
+		{primary.srv.URL, "unix:///var/run/wings.sock"},
+		{primary.srv.URL, "wings.example.com"},
+	} {
+		if _, err := newFailoverTransport(http.DefaultTransport, endpoints); err == nil {
+			t.Errorf("newFailoverTransport(%q) succeeded, want error", endpoints)
+		}
+	}
+}
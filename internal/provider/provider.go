@@ -4,19 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
 
-	"github.com/hashicorp/go-retryablehttp"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	tffunc "github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
@@ -34,6 +39,12 @@ const (
 	applicationJSON = "application/json"
 )
 
+const (
+	collisionCheckOff   = "off"
+	collisionCheckWarn  = "warn"
+	collisionCheckError = "error"
+)
+
 var _ provider.Provider = &WingsProvider{}
 
 type WingsProvider struct {
@@ -42,9 +53,37 @@ type WingsProvider struct {
 }
 
 type wingsProviderModel struct {
-	Endpoint types.String `tfsdk:"endpoint"`
-	APIKeyID types.String `tfsdk:"api_key_id"`
-	APIKey   types.String `tfsdk:"api_key"`
+	Endpoint          types.String   `tfsdk:"endpoint"`
+	FallbackEndpoints []types.String `tfsdk:"fallback_endpoints"`
+	APIKeyID          types.String   `tfsdk:"api_key_id"`
+	APIKey            types.String   `tfsdk:"api_key"`
+
+	BearerToken       types.String              `tfsdk:"bearer_token"`
+	OAuth2            *wingsProviderOAuth2Model `tfsdk:"oauth2"`
+	CredentialProcess types.String              `tfsdk:"credential_process"`
+	RequestSigning    types.Bool                `tfsdk:"request_signing"`
+
+	Profile               types.String `tfsdk:"profile"`
+	SharedCredentialsFile types.String `tfsdk:"shared_credentials_file"`
+
+	Project     types.String `tfsdk:"project"`
+	Environment types.String `tfsdk:"environment"`
+
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	TLSMinVersion      types.String `tfsdk:"tls_min_version"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+
+	ProxyURL        types.String            `tfsdk:"proxy_url"`
+	Headers         map[string]types.String `tfsdk:"headers"`
+	UserAgentSuffix types.String            `tfsdk:"user_agent_suffix"`
+
+	ValidateCredentials types.Bool `tfsdk:"validate_credentials"`
+
+	AdoptExisting  types.Bool   `tfsdk:"adopt_existing"`
+	CollisionCheck types.String `tfsdk:"collision_check"`
 }
 
 func (p *WingsProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -54,18 +93,168 @@ func (p *WingsProvider) Metadata(_ context.Context, _ provider.MetadataRequest,
 func (p *WingsProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Interact with Wings.",
+		MarkdownDescription: "Interact with Wings.\n\n" +
+			"Each of `endpoint`, `api_key_id` and `api_key` is resolved from, in order of precedence, " +
+			"the provider configuration, the `WINGS_ENDPOINT`, `WINGS_API_KEY_ID` and `WINGS_API_KEY` environment variables, " +
+			"and the selected profile of the shared credentials file.",
 		Attributes: map[string]schema.Attribute{
 			"endpoint": schema.StringAttribute{
-				Required: true,
+				Description: "The Wings API endpoint. A `unix:///path/to/wings.sock` endpoint reaches Wings over a Unix socket. " +
+					"May also be set with the `WINGS_ENDPOINT` environment variable.",
+				Optional: true,
+			},
+			"fallback_endpoints": schema.ListAttribute{
+				Description: "Endpoints of the same Wings API to fail over to, in order, when `endpoint` is unreachable or fails with a 5xx status. " +
+					"An endpoint that failed is only tried after the others for 30 seconds.",
+				ElementType: types.StringType,
+				Optional:    true,
 			},
 			"api_key_id": schema.StringAttribute{
-				Required:  true,
-				Sensitive: true,
+				Description: "The Wings API key ID. May also be set with the `WINGS_API_KEY_ID` environment variable.",
+				Optional:    true,
+				Sensitive:   true,
 			},
 			"api_key": schema.StringAttribute{
-				Required:  true,
+				Description: "The Wings API key. May also be set with the `WINGS_API_KEY` environment variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"bearer_token": schema.StringAttribute{
+				Description: "A static bearer token to authenticate with instead of an API key. " +
+					"May also be set with the `WINGS_BEARER_TOKEN` environment variable.",
+				Optional:  true,
 				Sensitive: true,
 			},
+			"credential_process": schema.StringAttribute{
+				Description: "A command that prints API key credentials as JSON with `api_key_id`, `api_key` and an optional RFC 3339 `expires_at`. " +
+					"The credentials are cached for the run and the command is run again once they expire. " +
+					"May also be set with the `WINGS_CREDENTIAL_PROCESS` environment variable.",
+				Optional: true,
+			},
+			"request_signing": schema.BoolAttribute{
+				Description: "Sign requests with an HMAC of the method, path, timestamp and body hash made with the API key, " +
+					"instead of sending the API key itself. Signed requests expire after five minutes. " +
+					"Only applies to API key authentication, including keys from `credential_process`.",
+				Optional: true,
+			},
+			"profile": schema.StringAttribute{
+				Description: "The profile of the shared credentials file to use. " +
+					"May also be set with the `WINGS_PROFILE` environment variable. Defaults to `default`.",
+				Optional: true,
+			},
+			"shared_credentials_file": schema.StringAttribute{
+				Description: "Path to the shared credentials file. " +
+					"May also be set with the `WINGS_SHARED_CREDENTIALS_FILE` environment variable. Defaults to `~/.wings/credentials`.",
+				Optional: true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Description: "PEM encoded CA certificates to trust in addition to the system roots, for endpoints behind an internal CA.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("ca_cert_file")),
+				},
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path to a file of PEM encoded CA certificates to trust in addition to the system roots.",
+				Optional:    true,
+			},
+			"client_cert": schema.StringAttribute{
+				Description: "PEM encoded client certificate for endpoints that require mutual TLS.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("client_key")),
+				},
+			},
+			"client_key": schema.StringAttribute{
+				Description: "PEM encoded private key of `client_cert`.",
+				Optional:    true,
+				Sensitive:   true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("client_cert")),
+				},
+			},
+			"tls_min_version": schema.StringAttribute{
+				Description: "The minimum TLS version to accept: `1.2` (default) or `1.3`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(tlsVersion12, tlsVersion13),
+				},
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Skip verification of the server certificate. Only for development endpoints.",
+				Optional:    true,
+			},
+			"proxy_url": schema.StringAttribute{
+				Description: "URL of an `http`, `https` or `socks5` proxy to reach Wings through. " +
+					"Defaults to the `HTTPS_PROXY`, `HTTP_PROXY` and `NO_PROXY` environment variables.",
+				Optional: true,
+			},
+			"headers": schema.MapAttribute{
+				Description: "Additional headers to send with every request, such as routing headers required by a gateway. " +
+					"They cannot override the authentication, `User-Agent` or `Content-Type` headers.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"user_agent_suffix": schema.StringAttribute{
+				Description: "Text appended to the User-Agent of every request, such as the name of the pipeline running Terraform. " +
+					"May also be set with the `WINGS_USER_AGENT_SUFFIX` environment variable.",
+				Optional: true,
+			},
+			"validate_credentials": schema.BoolAttribute{
+				Description: "Check the endpoint and credentials when the provider is configured, " +
+					"reporting unreachable endpoints, TLS problems and rejected credentials before any resource is planned. " +
+					"Costs one API request per run.",
+				Optional: true,
+			},
+			"project": schema.StringAttribute{
+				Description: "The default project of resources. Requires `environment`. " +
+					"May also be set with the `WINGS_PROJECT` environment variable. Without it, values are managed in the flat namespace of servers without projects.",
+				Optional: true,
+			},
+			"environment": schema.StringAttribute{
+				Description: "The default environment of resources, such as `staging` or `prod`. Requires `project`. " +
+					"May also be set with the `WINGS_ENVIRONMENT` environment variable.",
+				Optional: true,
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "Default for the `adopt_existing` attribute of resources. " +
+					"When true, creating a value that already exists updates it to match the configuration instead of failing.",
+				Optional: true,
+			},
+			"collision_check": schema.StringAttribute{
+				Description: "Whether planning a new value checks that its `value_id` is not already taken on the server: " +
+					"`off` (default), `warn` or `error`. The check costs one API request per new value.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(collisionCheckOff, collisionCheckWarn, collisionCheckError),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"oauth2": schema.SingleNestedBlock{
+				Description: "Authenticate with short-lived access tokens obtained with the OAuth2 client credentials grant. " +
+					"Tokens are cached and refreshed shortly before they expire.",
+				Attributes: map[string]schema.Attribute{
+					"token_url": schema.StringAttribute{
+						Description: "The token endpoint of the authorization server.",
+						Optional:    true,
+					},
+					"client_id": schema.StringAttribute{
+						Description: "The OAuth2 client ID.",
+						Optional:    true,
+					},
+					"client_secret": schema.StringAttribute{
+						Description: "The OAuth2 client secret. May also be set with the `WINGS_OAUTH2_CLIENT_SECRET` environment variable.",
+						Optional:    true,
+						Sensitive:   true,
+					},
+					"scopes": schema.ListAttribute{
+						Description: "The scopes to request.",
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -105,78 +294,297 @@ func (p *WingsProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		)
 	}
 
-	if resp.Diagnostics.HasError() {
-		return
+	if cfg.Profile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Unknown Wings Profile",
+			"The provider cannot create the Wings API client as there is an unknown configuration value for the shared credentials profile. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the WINGS_PROFILE environment variable.",
+		)
 	}
 
-	endpoint := os.Getenv("WINGS_ENDPOINT")
-	apiKeyID := os.Getenv("WINGS_API_KEY_ID")
-	apiKey := os.Getenv("WINGS_API_KEY")
-
-	if !cfg.Endpoint.IsNull() {
-		endpoint = cfg.Endpoint.ValueString()
+	if cfg.SharedCredentialsFile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("shared_credentials_file"),
+			"Unknown Wings Shared Credentials File",
+			"The provider cannot create the Wings API client as there is an unknown configuration value for the shared credentials file. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the WINGS_SHARED_CREDENTIALS_FILE environment variable.",
+		)
 	}
 
-	if !cfg.APIKeyID.IsNull() {
-		apiKeyID = cfg.APIKeyID.ValueString()
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if !cfg.APIKey.IsNull() {
-		apiKey = cfg.APIKey.ValueString()
+	creds, err := resolveCredentials(&cfg, os.Getenv)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid Wings Shared Credentials",
+			"The provider cannot read the Wings shared credentials file: "+err.Error(),
+		)
+		return
 	}
+	endpoint := creds.Endpoint
 
 	if endpoint == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("endpoint"),
 			"Missing Wings API Endpoint",
 			"The provider cannot create the Wings API client as there is a missing or empty value for the Wings API endpoint. "+
-				"Set the endpoint value in the configuration or use the WINGS_ENDPOINT environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+				"Set the endpoint value in the configuration, use the WINGS_ENDPOINT environment variable or set it in the shared credentials file. "+
+				"If any is already set, ensure the value is not empty.",
+		)
+	}
+
+	authMethod, err := selectAuthMethod(&cfg, creds)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Conflicting Wings Authentication Methods",
+			"The provider cannot create the Wings API client as "+err.Error()+".",
 		)
+		return
+	}
+
+	var oauth2ClientSecret string
+	switch authMethod {
+	case authMethodAPIKey:
+		if creds.APIKeyID == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("api_key_id"),
+				"Missing Wings API Key ID",
+				"The provider cannot create the Wings API client as there is a missing or empty value for the Wings API Key ID. "+
+					"Set the api_key_id value in the configuration, use the WINGS_API_KEY_ID environment variable or set it in the shared credentials file. "+
+					"If any is already set, ensure the value is not empty.",
+			)
+		}
+
+		if creds.APIKey == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("api_key"),
+				"Missing Wings API Key",
+				"The provider cannot create the Wings API client as there is a missing or empty value for the Wings API Key. "+
+					"Set the api_key value in the configuration, use the WINGS_API_KEY environment variable or set it in the shared credentials file. "+
+					"If any is already set, ensure the value is not empty.",
+			)
+		}
+	case authMethodCredentialProcess:
+		if _, err := splitCommand(creds.CredentialProcess); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("credential_process"),
+				"Invalid Wings Credential Process",
+				"The provider cannot create the Wings API client as the credential_process command is invalid: "+err.Error(),
+			)
+		}
+	case authMethodOAuth2:
+		for attr, v := range map[string]types.String{
+			"token_url": cfg.OAuth2.TokenURL,
+			"client_id": cfg.OAuth2.ClientID,
+		} {
+			if v.IsUnknown() || v.ValueString() == "" {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("oauth2").AtName(attr),
+					"Missing Wings OAuth2 Setting",
+					fmt.Sprintf("The provider cannot create the Wings API client as oauth2.%s is missing, empty or unknown.", attr),
+				)
+			}
+		}
+
+		oauth2ClientSecret = firstNonEmpty(cfg.OAuth2.ClientSecret.ValueString(), os.Getenv(envOAuth2ClientSecret))
+		if oauth2ClientSecret == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("oauth2").AtName("client_secret"),
+				"Missing Wings OAuth2 Client Secret",
+				"The provider cannot create the Wings API client as there is a missing or empty value for the OAuth2 client secret. "+
+					"Set the client_secret value in the oauth2 block or use the WINGS_OAUTH2_CLIENT_SECRET environment variable.",
+			)
+		}
 	}
 
-	if apiKeyID == "" {
+	if cfg.RequestSigning.ValueBool() && authMethod != authMethodAPIKey && authMethod != authMethodCredentialProcess {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("api_key_id"),
-			"Missing Wings API Key",
-			"The provider cannot create the Wings API client as there is a missing or empty value for the Wings API Key. "+
-				"Set the api_key value in the configuration or use the WINGS_API_KEY environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+			path.Root("request_signing"),
+			"Unsupported Wings Request Signing",
+			fmt.Sprintf("Request signing requires API key authentication, but the provider is configured to use %s.", authMethod),
 		)
 	}
 
-	if apiKey == "" {
+	endpoint, socket, err := splitUnixEndpoint(endpoint)
+	if err != nil {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("api_key"),
-			"Missing Wings API Key",
-			"The provider cannot create the Wings API client as there is a missing or empty value for the Wings API Key. "+
-				"Set the api_key value in the configuration or use the WINGS_API_KEY environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+			path.Root("endpoint"),
+			"Invalid Wings API Endpoint",
+			"The provider cannot create the Wings API client as the endpoint is invalid: "+err.Error(),
+		)
+	}
+
+	scope := model.Scope{
+		Project:     firstNonEmpty(cfg.Project.ValueString(), os.Getenv(envProject)),
+		Environment: firstNonEmpty(cfg.Environment.ValueString(), os.Getenv(envEnvironment)),
+	}
+	if (scope.Project == "") != (scope.Environment == "") {
+		resp.Diagnostics.AddError(
+			"Incomplete Wings Scope",
+			"The provider cannot create the Wings API client as only one of project and environment is set. "+
+				"Set both, in the configuration or with the WINGS_PROJECT and WINGS_ENVIRONMENT environment variables, or neither.",
+		)
+	}
+
+	var fallbackEndpoints []string
+	for i, v := range cfg.FallbackEndpoints {
+		if v.IsUnknown() || v.ValueString() == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("fallback_endpoints").AtListIndex(i),
+				"Invalid Wings Fallback Endpoint",
+				"The provider cannot create the Wings API client as a fallback endpoint is empty or unknown.",
+			)
+			continue
+		}
+		fallbackEndpoints = append(fallbackEndpoints, v.ValueString())
+	}
+	if len(fallbackEndpoints) > 0 && socket != "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("fallback_endpoints"),
+			"Invalid Wings Fallback Endpoint",
+			"Fallback endpoints cannot be used with a unix:// endpoint.",
+		)
+	}
+
+	headers := make(map[string]string, len(cfg.Headers))
+	for name, v := range cfg.Headers {
+		if isReservedHeader(name) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("headers").AtMapKey(name),
+				"Reserved Wings Request Header",
+				fmt.Sprintf("The %s header is set by the provider and cannot be overridden.", name),
+			)
+			continue
+		}
+		headers[name] = v.ValueString()
+	}
+
+	transport, err := newTransport(&transportOptions{
+		caCertPEM:          cfg.CACertPEM.ValueString(),
+		caCertFile:         cfg.CACertFile.ValueString(),
+		clientCertPEM:      cfg.ClientCert.ValueString(),
+		clientKeyPEM:       cfg.ClientKey.ValueString(),
+		minTLSVersion:      cfg.TLSMinVersion.ValueString(),
+		insecureSkipVerify: cfg.InsecureSkipVerify.ValueBool(),
+		proxyURL:           cfg.ProxyURL.ValueString(),
+		unixSocket:         socket,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid Wings Transport Configuration",
+			"The provider cannot create the Wings API client as the transport configuration is invalid: "+err.Error(),
+		)
+	}
+
+	if cfg.InsecureSkipVerify.ValueBool() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"Wings Server Certificate Not Verified",
+			"insecure_skip_verify is enabled, so the provider does not verify the identity of the Wings API. "+
+				"Only use it with development endpoints.",
 		)
 	}
 
+	var failover *failoverTransport
+	if len(fallbackEndpoints) > 0 && socket == "" {
+		failover, err = newFailoverTransport(transport, append([]string{endpoint}, fallbackEndpoints...))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("fallback_endpoints"),
+				"Invalid Wings Fallback Endpoint",
+				"The provider cannot create the Wings API client as "+err.Error()+".",
+			)
+		} else if cfg.RequestSigning.ValueBool() && !failover.samePaths() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("fallback_endpoints"),
+				"Invalid Wings Fallback Endpoint",
+				"Signed requests cover the request path, so with request_signing all endpoints must have the same path.",
+			)
+		}
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	runID, err := randomNonce()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Create Wings API Client",
+			"The provider cannot generate a run ID: "+err.Error(),
+		)
+		return
+	}
+	ua := userAgent(p.version, req.TerraformVersion, firstNonEmpty(cfg.UserAgentSuffix.ValueString(), os.Getenv(envUserAgentSuffix)))
+
 	ctx = tflog.SetField(ctx, "wings_endpoint", endpoint)
-	ctx = tflog.SetField(ctx, "wings_api_key_id", apiKeyID)
-	ctx = tflog.SetField(ctx, "wings_api_key", apiKey)
-	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "wings_api_key")
+	ctx = tflog.SetField(ctx, "wings_run_id", runID)
+	if socket != "" {
+		ctx = tflog.SetField(ctx, "wings_unix_socket", socket)
+	}
+	ctx = tflog.SetField(ctx, "wings_auth_method", authMethod)
+	ctx = tflog.SetField(ctx, "wings_api_key_id", creds.APIKeyID)
 
 	tflog.Debug(ctx, "Creating Wings client")
 
 	if p.config == nil {
-		retryClient := retryablehttp.NewClient()
-		retryClient.RetryMax = 5
+		var rt http.RoundTripper = transport
+		if failover != nil {
+			rt = failover
+		}
+		retryClient := newRetryClient(rt)
 		rc := retryClient.StandardClient()
+
+		var auth authenticator
+		switch authMethod {
+		case authMethodOAuth2:
+			// Token requests have a client of their own, whose retries are
+			// not authenticated for the Wings API.
+			auth = newOAuth2ClientCredentialsAuth(cfg.OAuth2, oauth2ClientSecret, newRetryClient(rt).StandardClient())
+		case authMethodBearerToken:
+			auth = &bearerTokenAuth{token: creds.BearerToken}
+		default:
+			var source apiKeySource = &staticAPIKey{keyID: creds.APIKeyID, key: creds.APIKey}
+			if authMethod == authMethodCredentialProcess {
+				source = newCredentialProcess(creds.CredentialProcess)
+			}
+			if cfg.RequestSigning.ValueBool() {
+				auth = newHMACSigningAuth(source)
+			} else {
+				auth = &apiKeyAuth{source: source}
+			}
+		}
+		authenticateRetries(retryClient, endpoint, auth)
+
 		p.config = &config{
-			ua:       "terraform-provider-wings",
-			keyID:    apiKeyID,
-			key:      apiKey,
+			ua:       ua,
+			runID:    runID,
+			auth:     auth,
 			endpoint: endpoint,
 			client:   rc,
+			headers:  headers,
+
+			scope: scope,
+
+			adoptExisting:  cfg.AdoptExisting.ValueBool(),
+			collisionCheck: cfg.CollisionCheck.ValueString(),
+		}
+	}
+
+	if cfg.ValidateCredentials.ValueBool() {
+		identity, err := p.config.WhoAmI(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError(describeConnectionError(endpoint, err))
+			return
 		}
+		tflog.Debug(ctx, "Validated Wings credentials", map[string]any{
+			"wings_identity_id":   identity.ID,
+			"wings_identity_name": identity.Name,
+			"wings_identity_type": identity.Type,
+		})
 	}
 
 	resp.DataSourceData = p.config
@@ -192,6 +600,14 @@ func (p *WingsProvider) DataSources(_ context.Context) []func() datasource.DataS
 func (p *WingsProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewValueResource,
+		NewProjectResource,
+		NewEnvironmentResource,
+		NewAPIKeyResource,
+		NewValueEnvironmentOverrideResource,
+		NewValueTargetingRuleResource,
+		NewSegmentResource,
+		NewSegmentListResource,
+		NewContextSchemaResource,
 	}
 }
 
@@ -211,14 +627,36 @@ func New(version string) func() provider.Provider {
 
 type config struct {
 	ua       string
-	keyID    string
-	key      string
+	runID    string
+	requests atomic.Uint64
+	auth     authenticator
 	endpoint string
 	client   *http.Client
+	headers  map[string]string
+
+	capabilities serverCapabilities
+
+	// planned are the values planned in this run, to check prerequisites.
+	planned plannedValues
+
+	// scope is the default project and environment of values.
+	scope model.Scope
+
+	adoptExisting  bool
+	collisionCheck string
 }
 
-func (c *config) GetValue(ctx context.Context, id string) (*model.Value, error) {
-	u, err := url.JoinPath(c.endpoint, "values", id)
+// scopedURL joins elem onto the endpoint, below the project and environment
+// of scope unless it is the zero Scope.
+func (c *config) scopedURL(scope model.Scope, elem ...string) (string, error) {
+	if scope.IsZero() {
+		return url.JoinPath(c.endpoint, elem...)
+	}
+	return url.JoinPath(c.endpoint, append([]string{"projects", scope.Project, "environments", scope.Environment}, elem...)...)
+}
+
+func (c *config) GetValue(ctx context.Context, scope model.Scope, id string) (*model.Value, error) {
+	u, err := c.scopedURL(scope, "values", id)
 	if err != nil {
 		return nil, err
 	}
@@ -235,8 +673,7 @@ func (c *config) GetValue(ctx context.Context, id string) (*model.Value, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, %s", resp.StatusCode, string(b))
+		return nil, newAPIError(resp)
 	}
 
 	value := new(model.Value)
@@ -245,7 +682,7 @@ func (c *config) GetValue(ctx context.Context, id string) (*model.Value, error)
 }
 
 func (c *config) CreateValue(ctx context.Context, value *model.Value) (*model.Value, error) {
-	u, err := url.JoinPath(c.endpoint, "values")
+	u, err := c.scopedURL(value.Scope(), "values")
 	if err != nil {
 		return nil, err
 	}
@@ -267,8 +704,7 @@ func (c *config) CreateValue(ctx context.Context, value *model.Value) (*model.Va
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, %s", resp.StatusCode, string(b))
+		return nil, newAPIError(resp)
 	}
 
 	v := new(model.Value)
@@ -277,7 +713,7 @@ func (c *config) CreateValue(ctx context.Context, value *model.Value) (*model.Va
 }
 
 func (c *config) UpdateValue(ctx context.Context, value *model.Value) (*model.Value, error) {
-	u, err := url.JoinPath(c.endpoint, "values", value.ID)
+	u, err := c.scopedURL(value.Scope(), "values", value.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -299,8 +735,7 @@ func (c *config) UpdateValue(ctx context.Context, value *model.Value) (*model.Va
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, %s", resp.StatusCode, string(b))
+		return nil, newAPIError(resp)
 	}
 
 	v := new(model.Value)
@@ -308,8 +743,8 @@ func (c *config) UpdateValue(ctx context.Context, value *model.Value) (*model.Va
 	return v, err
 }
 
-func (c *config) DeleteValue(ctx context.Context, id string) error {
-	u, err := url.JoinPath(c.endpoint, "values", id)
+func (c *config) DeleteValue(ctx context.Context, scope model.Scope, id string) error {
+	u, err := c.scopedURL(scope, "values", id)
 	if err != nil {
 		return err
 	}
@@ -326,18 +761,475 @@ func (c *config) DeleteValue(ctx context.Context, id string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
-		b, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status code: %d, %s", resp.StatusCode, string(b))
+		return newAPIError(resp)
+	}
+
+	return nil
+}
+
+// maxValueConflicts is how often ModifyValue retries a value that changed
+// between reading and updating it.
+const maxValueConflicts = 5
+
+// ModifyValue updates the value id in scope to the value returned by modify,
+// which is passed the current value. The update fails with a conflict if the
+// value changed after it was read, in which case it is read and modified
+// again, so that concurrent changes to other parts of the value are kept.
+func (c *config) ModifyValue(ctx context.Context, scope model.Scope, id string, modify func(current *model.Value) (*model.Value, error)) (*model.Value, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := c.GetValue(ctx, scope, id)
+		if err != nil {
+			return nil, err
+		}
+		value, err := modify(current)
+		if err != nil {
+			return nil, err
+		}
+		value.Project, value.Environment = scope.Project, scope.Environment
+		value.CreatedAt, value.UpdatedAt, value.UpdatedBy = nil, nil, ""
+		value.Revision = current.Revision
+
+		updated, err := c.UpdateValue(ctx, value)
+		if isConflict(err) && attempt < maxValueConflicts {
+			tflog.Debug(ctx, "Wings value changed concurrently, retrying", map[string]any{
+				"value_id": id,
+				"attempt":  attempt,
+			})
+			continue
+		}
+		return updated, err
+	}
+}
+
+// GetValueOverride returns the override of the base value id in scope for
+// environment.
+func (c *config) GetValueOverride(ctx context.Context, scope model.Scope, id, environment string) (*model.ValueOverride, error) {
+	u, err := c.scopedURL(scope, "values", id, "overrides", environment)
+	if err != nil {
+		return nil, err
+	}
+	override := new(model.ValueOverride)
+	return override, c.doJSON(ctx, http.MethodGet, u, nil, override)
+}
+
+// PutValueOverride creates or replaces the override of the base value id in
+// scope for environment.
+func (c *config) PutValueOverride(ctx context.Context, scope model.Scope, id, environment string, override *model.ValueOverride) (*model.ValueOverride, error) {
+	u, err := c.scopedURL(scope, "values", id, "overrides", environment)
+	if err != nil {
+		return nil, err
+	}
+	updated := new(model.ValueOverride)
+	return updated, c.doJSON(ctx, http.MethodPut, u, override, updated)
+}
+
+func (c *config) DeleteValueOverride(ctx context.Context, scope model.Scope, id, environment string) error {
+	u, err := c.scopedURL(scope, "values", id, "overrides", environment)
+	if err != nil {
+		return err
+	}
+	if err := c.doJSON(ctx, http.MethodDelete, u, nil, nil); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *config) GetProject(ctx context.Context, key string) (*model.Project, error) {
+	u, err := url.JoinPath(c.endpoint, "projects", key)
+	if err != nil {
+		return nil, err
+	}
+	project := new(model.Project)
+	return project, c.doJSON(ctx, http.MethodGet, u, nil, project)
+}
+
+func (c *config) CreateProject(ctx context.Context, project *model.Project) (*model.Project, error) {
+	u, err := url.JoinPath(c.endpoint, "projects")
+	if err != nil {
+		return nil, err
+	}
+	created := new(model.Project)
+	return created, c.doJSON(ctx, http.MethodPost, u, project, created)
+}
+
+func (c *config) UpdateProject(ctx context.Context, project *model.Project) (*model.Project, error) {
+	u, err := url.JoinPath(c.endpoint, "projects", project.Key)
+	if err != nil {
+		return nil, err
+	}
+	updated := new(model.Project)
+	return updated, c.doJSON(ctx, http.MethodPut, u, project, updated)
+}
+
+func (c *config) DeleteProject(ctx context.Context, key string) error {
+	u, err := url.JoinPath(c.endpoint, "projects", key)
+	if err != nil {
+		return err
+	}
+	if err := c.doJSON(ctx, http.MethodDelete, u, nil, nil); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *config) GetEnvironment(ctx context.Context, project, key string) (*model.Environment, error) {
+	u, err := url.JoinPath(c.endpoint, "projects", project, "environments", key)
+	if err != nil {
+		return nil, err
+	}
+	env := new(model.Environment)
+	return env, c.doJSON(ctx, http.MethodGet, u, nil, env)
+}
+
+func (c *config) CreateEnvironment(ctx context.Context, env *model.Environment) (*model.Environment, error) {
+	u, err := url.JoinPath(c.endpoint, "projects", env.Project, "environments")
+	if err != nil {
+		return nil, err
+	}
+	created := new(model.Environment)
+	return created, c.doJSON(ctx, http.MethodPost, u, env, created)
+}
+
+func (c *config) UpdateEnvironment(ctx context.Context, env *model.Environment) (*model.Environment, error) {
+	u, err := url.JoinPath(c.endpoint, "projects", env.Project, "environments", env.Key)
+	if err != nil {
+		return nil, err
+	}
+	updated := new(model.Environment)
+	return updated, c.doJSON(ctx, http.MethodPut, u, env, updated)
+}
+
+func (c *config) DeleteEnvironment(ctx context.Context, project, key string) error {
+	u, err := url.JoinPath(c.endpoint, "projects", project, "environments", key)
+	if err != nil {
+		return err
+	}
+	if err := c.doJSON(ctx, http.MethodDelete, u, nil, nil); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *config) GetSegment(ctx context.Context, key string) (*model.Segment, error) {
+	u, err := url.JoinPath(c.endpoint, "segments", key)
+	if err != nil {
+		return nil, err
+	}
+	segment := new(model.Segment)
+	return segment, c.doJSON(ctx, http.MethodGet, u, nil, segment)
+}
+
+func (c *config) CreateSegment(ctx context.Context, segment *model.Segment) (*model.Segment, error) {
+	u, err := url.JoinPath(c.endpoint, "segments")
+	if err != nil {
+		return nil, err
+	}
+	created := new(model.Segment)
+	return created, c.doJSON(ctx, http.MethodPost, u, segment, created)
+}
+
+func (c *config) UpdateSegment(ctx context.Context, segment *model.Segment) (*model.Segment, error) {
+	u, err := url.JoinPath(c.endpoint, "segments", segment.Key)
+	if err != nil {
+		return nil, err
+	}
+	updated := new(model.Segment)
+	return updated, c.doJSON(ctx, http.MethodPut, u, segment, updated)
+}
+
+func (c *config) DeleteSegment(ctx context.Context, key string) error {
+	u, err := url.JoinPath(c.endpoint, "segments", key)
+	if err != nil {
+		return err
+	}
+	if err := c.doJSON(ctx, http.MethodDelete, u, nil, nil); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *config) GetSegmentList(ctx context.Context, key string) (*model.SegmentList, error) {
+	u, err := url.JoinPath(c.endpoint, "segment-lists", key)
+	if err != nil {
+		return nil, err
+	}
+	list := new(model.SegmentList)
+	return list, c.doJSON(ctx, http.MethodGet, u, nil, list)
+}
+
+func (c *config) CreateSegmentList(ctx context.Context, list *model.SegmentList) (*model.SegmentList, error) {
+	u, err := url.JoinPath(c.endpoint, "segment-lists")
+	if err != nil {
+		return nil, err
+	}
+	created := new(model.SegmentList)
+	return created, c.doJSON(ctx, http.MethodPost, u, list, created)
+}
+
+func (c *config) UpdateSegmentList(ctx context.Context, list *model.SegmentList) (*model.SegmentList, error) {
+	u, err := url.JoinPath(c.endpoint, "segment-lists", list.Key)
+	if err != nil {
+		return nil, err
 	}
+	updated := new(model.SegmentList)
+	return updated, c.doJSON(ctx, http.MethodPut, u, list, updated)
+}
 
+func (c *config) DeleteSegmentList(ctx context.Context, key string) error {
+	u, err := url.JoinPath(c.endpoint, "segment-lists", key)
+	if err != nil {
+		return err
+	}
+	if err := c.doJSON(ctx, http.MethodDelete, u, nil, nil); err != nil && !isNotFound(err) {
+		return err
+	}
 	return nil
 }
 
+// ListSegmentListMembers returns all members of the segment list key, reading
+// them page by page.
+func (c *config) ListSegmentListMembers(ctx context.Context, key string) ([]string, error) {
+	u, err := url.JoinPath(c.endpoint, "segment-lists", key, "members")
+	if err != nil {
+		return nil, err
+	}
+
+	var members []string
+	cursor := ""
+	for {
+		pageURL := u
+		if cursor != "" {
+			pageURL += "?" + url.Values{"cursor": {cursor}}.Encode()
+		}
+		page := new(model.SegmentListMembers)
+		if err := c.doJSON(ctx, http.MethodGet, pageURL, nil, page); err != nil {
+			return nil, err
+		}
+		members = append(members, page.Members...)
+		if page.NextCursor == "" {
+			return members, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// ChangeSegmentListMembers adds and removes members of the segment list key.
+func (c *config) ChangeSegmentListMembers(ctx context.Context, key string, changes *model.SegmentListChanges) error {
+	u, err := url.JoinPath(c.endpoint, "segment-lists", key, "members")
+	if err != nil {
+		return err
+	}
+	return c.doJSON(ctx, http.MethodPost, u, changes, nil)
+}
+
+func (c *config) GetContextSchema(ctx context.Context, key string) (*model.ContextSchema, error) {
+	u, err := url.JoinPath(c.endpoint, "context-schemas", key)
+	if err != nil {
+		return nil, err
+	}
+	schema := new(model.ContextSchema)
+	return schema, c.doJSON(ctx, http.MethodGet, u, nil, schema)
+}
+
+func (c *config) CreateContextSchema(ctx context.Context, schema *model.ContextSchema) (*model.ContextSchema, error) {
+	u, err := url.JoinPath(c.endpoint, "context-schemas")
+	if err != nil {
+		return nil, err
+	}
+	created := new(model.ContextSchema)
+	return created, c.doJSON(ctx, http.MethodPost, u, schema, created)
+}
+
+func (c *config) UpdateContextSchema(ctx context.Context, schema *model.ContextSchema) (*model.ContextSchema, error) {
+	u, err := url.JoinPath(c.endpoint, "context-schemas", schema.Key)
+	if err != nil {
+		return nil, err
+	}
+	updated := new(model.ContextSchema)
+	return updated, c.doJSON(ctx, http.MethodPut, u, schema, updated)
+}
+
+func (c *config) DeleteContextSchema(ctx context.Context, key string) error {
+	u, err := url.JoinPath(c.endpoint, "context-schemas", key)
+	if err != nil {
+		return err
+	}
+	if err := c.doJSON(ctx, http.MethodDelete, u, nil, nil); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *config) GetAPIKey(ctx context.Context, id string) (*model.APIKey, error) {
+	u, err := url.JoinPath(c.endpoint, "api-keys", id)
+	if err != nil {
+		return nil, err
+	}
+	key := new(model.APIKey)
+	return key, c.doJSON(ctx, http.MethodGet, u, nil, key)
+}
+
+func (c *config) CreateAPIKey(ctx context.Context, key *model.APIKey) (*model.APIKey, error) {
+	u, err := url.JoinPath(c.endpoint, "api-keys")
+	if err != nil {
+		return nil, err
+	}
+	created := new(model.APIKey)
+	return created, c.doJSON(ctx, http.MethodPost, u, key, created)
+}
+
+func (c *config) UpdateAPIKey(ctx context.Context, key *model.APIKey) (*model.APIKey, error) {
+	u, err := url.JoinPath(c.endpoint, "api-keys", key.ID)
+	if err != nil {
+		return nil, err
+	}
+	updated := new(model.APIKey)
+	return updated, c.doJSON(ctx, http.MethodPut, u, key, updated)
+}
+
+// RevokeAPIKey revokes the key, which stops authenticating immediately.
+func (c *config) RevokeAPIKey(ctx context.Context, id string) error {
+	u, err := url.JoinPath(c.endpoint, "api-keys", id)
+	if err != nil {
+		return err
+	}
+	if err := c.doJSON(ctx, http.MethodDelete, u, nil, nil); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// doJSON sends in as the JSON body of a request to u, if it is not nil, and
+// decodes the JSON response into out, if it is not nil.
+func (c *config) doJSON(ctx context.Context, method, u string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		j, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(j)
+	}
+
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return newAPIError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// apiError is returned by the client when the Wings API responds with an error status.
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func newAPIError(resp *http.Response) *apiError {
+	b, _ := io.ReadAll(resp.Body)
+	return &apiError{
+		StatusCode: resp.StatusCode,
+		Body:       string(b),
+	}
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, %s", e.StatusCode, e.Body)
+}
+
+func isNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+func isConflict(err error) bool {
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+func isUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+func isForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+func hasStatusCode(err error, code int) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
+
+// WhoAmI returns the identity the credentials authenticate as.
+func (c *config) WhoAmI(ctx context.Context) (*model.Identity, error) {
+	u, err := url.JoinPath(c.endpoint, "whoami")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp)
+	}
+
+	identity := new(model.Identity)
+	err = json.NewDecoder(resp.Body).Decode(identity)
+	return identity, err
+}
+
+// reservedHeaders are set by the provider itself on every request.
+var reservedHeaders = []string{
+	headerUA, headerContentType, headerRequestID, headerAuthorization, headerKeyID, headerKey,
+	headerTimestamp, headerExpires, headerNonce, headerContentSHA256, headerSignature,
+}
+
+func isReservedHeader(name string) bool {
+	return slices.ContainsFunc(reservedHeaders, func(h string) bool {
+		return strings.EqualFold(h, name)
+	})
+}
+
 func (c *config) do(ctx context.Context, req *http.Request) (*http.Response, error) {
-	req.Header.Set(headerKeyID, c.keyID)
-	req.Header.Set(headerKey, c.key)
+	req = req.WithContext(ctx)
+	for name, v := range c.headers {
+		req.Header.Set(name, v)
+	}
+	if c.auth != nil {
+		if err := c.auth.authenticate(ctx, req); err != nil {
+			return nil, err
+		}
+	}
 	req.Header.Set(headerUA, c.ua)
 	req.Header.Set(headerContentType, applicationJSON)
-	req.WithContext(ctx)
+	if c.runID != "" {
+		// Retries keep the ID, as they are copies of this request.
+		requestID := fmt.Sprintf("%s-%d", c.runID, c.requests.Add(1))
+		req.Header.Set(headerRequestID, requestID)
+		tflog.Debug(ctx, "Sending Wings request", map[string]any{
+			"request_id": requestID,
+			"method":     req.Method,
+			"url":        req.URL.String(),
+		})
+	}
 	return c.client.Do(req)
 }
@@ -0,0 +1,331 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+const (
+	permissionRead  = "read"
+	permissionWrite = "write"
+)
+
+var (
+	_ resource.Resource                   = &APIKeyResource{}
+	_ resource.ResourceWithModifyPlan     = &APIKeyResource{}
+	_ resource.ResourceWithImportState    = &APIKeyResource{}
+	_ resource.ResourceWithValidateConfig = &APIKeyResource{}
+)
+
+func NewAPIKeyResource() resource.Resource {
+	return &APIKeyResource{}
+}
+
+type APIKeyResource struct {
+	c *config
+}
+
+type apiKeyResource struct {
+	ID              types.String            `tfsdk:"id"`
+	Name            types.String            `tfsdk:"name"`
+	Description     types.String            `tfsdk:"description"`
+	Project         types.String            `tfsdk:"project"`
+	Environment     types.String            `tfsdk:"environment"`
+	Permissions     []types.String          `tfsdk:"permissions"`
+	ExpiresAt       types.String            `tfsdk:"expires_at"`
+	RotationTrigger map[string]types.String `tfsdk:"rotation_trigger"`
+	Secret          types.String            `tfsdk:"secret"`
+	CreatedAt       types.String            `tfsdk:"created_at"`
+}
+
+func (a *APIKeyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_api_key"
+}
+
+func (a *APIKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Wings API key resource. Issues a key for a service, optionally scoped to a project and environment. " +
+			"The secret is only returned when the key is created. Destroying the resource revokes the key.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of this API Key, which is sent as the API key ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of this API Key, such as the service using it.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"project": schema.StringAttribute{
+				Description: "The project this API Key is limited to. Without it, the key applies to all projects. Changing it replaces the API Key.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment": schema.StringAttribute{
+				Description: "The environment of `project` this API Key is limited to. Changing it replaces the API Key.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("project")),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"permissions": schema.SetAttribute{
+				Description: "The permissions of this API Key: `read`, `write` or both. Changing them replaces the API Key.",
+				ElementType: types.StringType,
+				Required:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(permissionRead, permissionWrite)),
+				},
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.RequiresReplace(),
+				},
+			},
+			"expires_at": schema.StringAttribute{
+				Description: "The time this API Key expires, in RFC 3339 format. Without it, the key does not expire. Changing it replaces the API Key.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rotation_trigger": schema.MapAttribute{
+				Description: "Arbitrary values that replace the API Key with a new one, and so rotate its secret, when they change. " +
+					"Combine with `create_before_destroy` to issue the new key before the old one is revoked.",
+				ElementType: types.StringType,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"secret": schema.StringAttribute{
+				Description: "The secret of this API Key. It is only returned when the key is created, so it is null for imported keys.",
+				Computed:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"created_at": schema.StringAttribute{
+				Description: "The time this API Key was created, in RFC 3339 format.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (a *APIKeyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var expiresAt types.String
+	diags := req.Config.GetAttribute(ctx, path.Root("expires_at"), &expiresAt)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || expiresAt.IsNull() || expiresAt.IsUnknown() {
+		return
+	}
+	if _, err := time.Parse(time.RFC3339, expiresAt.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("expires_at"), "Invalid expiry", fmt.Sprintf("expires_at must be an RFC 3339 time: %s", err))
+	}
+}
+
+func (a *APIKeyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || a.c == nil {
+		return
+	}
+	a.c.requireCapability(ctx, capabilityAPIKeys, "API key management", path.Root("name"), &resp.Diagnostics)
+}
+
+func (a *apiKeyResource) apiKey() (*model.APIKey, error) {
+	key := &model.APIKey{
+		ID:          a.ID.ValueString(),
+		Name:        a.Name.ValueString(),
+		Description: a.Description.ValueString(),
+		Project:     a.Project.ValueString(),
+		Environment: a.Environment.ValueString(),
+		Permissions: make([]string, 0, len(a.Permissions)),
+	}
+	for _, p := range a.Permissions {
+		key.Permissions = append(key.Permissions, p.ValueString())
+	}
+	if !a.ExpiresAt.IsNull() {
+		t, err := time.Parse(time.RFC3339, a.ExpiresAt.ValueString())
+		if err != nil {
+			return nil, err
+		}
+		key.ExpiresAt = &t
+	}
+	return key, nil
+}
+
+// apiKeyState returns the state of key. The secret and rotation trigger are
+// not known to the server, so they are taken from prior.
+func apiKeyState(prior *apiKeyResource, key *model.APIKey) *apiKeyResource {
+	state := &apiKeyResource{
+		ID:              types.StringValue(key.ID),
+		Name:            types.StringValue(key.Name),
+		Description:     optionalString(key.Description),
+		Project:         optionalString(key.Project),
+		Environment:     optionalString(key.Environment),
+		Permissions:     make([]types.String, 0, len(key.Permissions)),
+		ExpiresAt:       timeValue(key.ExpiresAt),
+		RotationTrigger: prior.RotationTrigger,
+		Secret:          prior.Secret,
+		CreatedAt:       timeValue(key.CreatedAt),
+	}
+	for _, p := range key.Permissions {
+		state.Permissions = append(state.Permissions, types.StringValue(p))
+	}
+
+	// Keep the configured representation of an unchanged expiry, such as a
+	// different time zone offset.
+	if t, err := time.Parse(time.RFC3339, prior.ExpiresAt.ValueString()); err == nil && key.ExpiresAt != nil && t.Equal(*key.ExpiresAt) {
+		state.ExpiresAt = prior.ExpiresAt
+	}
+	return state
+}
+
+func (a *APIKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan apiKeyResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := plan.apiKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating API key", "Invalid Attribute(s): "+err.Error())
+		return
+	}
+
+	created, err := a.c.CreateAPIKey(ctx, key)
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating API key", err.Error())
+		return
+	}
+	if created.Secret == "" {
+		resp.Diagnostics.AddError("Error creating API key", fmt.Sprintf("The Wings API did not return the secret of API key %q.", created.ID))
+		return
+	}
+
+	plan.ID = types.StringValue(created.ID)
+	plan.Secret = types.StringValue(created.Secret)
+	plan.CreatedAt = timeValue(created.CreatedAt)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (a *APIKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state apiKeyResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := a.c.GetAPIKey(ctx, state.ID.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading API key", err.Error())
+		return
+	}
+	// A key revoked outside of Terraform cannot be used again, so it has to
+	// be issued anew.
+	if key.Revoked {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	diags = resp.State.Set(ctx, apiKeyState(&state, key))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (a *APIKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan apiKeyResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := plan.apiKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating API key", "Invalid Attribute(s): "+err.Error())
+		return
+	}
+
+	// Only the name and description can change without replacing the key.
+	if _, err := a.c.UpdateAPIKey(ctx, key); err != nil {
+		resp.Diagnostics.AddError("Error updating API key", err.Error())
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (a *APIKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state apiKeyResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := a.c.RevokeAPIKey(ctx, state.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error revoking API key", err.Error())
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (a *APIKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	key, err := a.c.GetAPIKey(ctx, req.ID)
+	if isNotFound(err) || (err == nil && key.Revoked) {
+		resp.Diagnostics.AddError("Cannot import non-existent API key", fmt.Sprintf("API key %q does not exist or was revoked.", req.ID))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading API key", err.Error())
+		return
+	}
+
+	prior := &apiKeyResource{Secret: types.StringNull(), ExpiresAt: types.StringNull()}
+	diags := resp.State.Set(ctx, apiKeyState(prior, key))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (a *APIKeyResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	a.c = req.ProviderData.(*config)
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jarcoal/httpmock"
+)
+
+var (
+	//go:embed testdata/api_key.json
+	apiKeyTestdata string
+	//go:embed testdata/api_key_read.json
+	apiKeyReadTestdata string
+	//go:embed testdata/api_key_revoked.json
+	apiKeyRevokedTestdata string
+)
+
+func TestAccResourceWingsAPIKey(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, `{"version":"1.4.0","capabilities":["projects","api_keys"]}`),
+	)
+	mock.RegisterResponder(
+		http.MethodPost,
+		"http://localhost:8018/api-keys",
+		httpmock.NewStringResponder(200, apiKeyTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/api-keys/key_3f9a2c",
+		httpmock.NewStringResponder(200, apiKeyReadTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodPut,
+		"http://localhost:8018/api-keys/key_3f9a2c",
+		httpmock.NewStringResponder(200, apiKeyReadTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		"http://localhost:8018/api-keys/key_3f9a2c",
+		httpmock.NewStringResponder(204, ""),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		CheckDestroy: func(*terraform.State) error {
+			if n := mock.GetCallCountInfo()["DELETE http://localhost:8018/api-keys/key_3f9a2c"]; n == 0 {
+				return fmt.Errorf("API key was not revoked")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceAPIKey("1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_api_key.checkout", "id", "key_3f9a2c"),
+					resource.TestCheckResourceAttr("wings_api_key.checkout", "permissions.#", "1"),
+					resource.TestCheckResourceAttr("wings_api_key.checkout", "expires_at", "2027-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr("wings_api_key.checkout", "created_at", "2026-10-18T09:30:00Z"),
+					// The secret is kept from creation, although reads do not return it.
+					resource.TestCheckResourceAttr("wings_api_key.checkout", "secret", "wk_live_7c1e0b9d4a"),
+				),
+			},
+			{
+				ResourceName:            "wings_api_key.checkout",
+				ImportState:             true,
+				ImportStateId:           "key_3f9a2c",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"secret", "rotation_trigger"},
+			},
+			{
+				// Changing the rotation trigger issues a new key.
+				Config: providerConfig + testAccResourceAPIKey("2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("wings_api_key.checkout", plancheck.ResourceActionReplace),
+					},
+				},
+			},
+			{
+				// A key revoked outside of Terraform is issued again.
+				PreConfig: func() {
+					mock.RegisterResponder(
+						http.MethodGet,
+						"http://localhost:8018/api-keys/key_3f9a2c",
+						httpmock.NewStringResponder(200, apiKeyRevokedTestdata),
+					)
+				},
+				Config:             providerConfig + testAccResourceAPIKey("2"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccResourceAPIKey(rotation string) string {
+	return fmt.Sprintf(`
+resource "wings_api_key" "checkout" {
+  name        = "checkout-service"
+  description = "Read access for checkout"
+  project     = "payments"
+  environment = "staging"
+  permissions = ["read"]
+  expires_at  = "2027-01-01T00:00:00Z"
+
+  rotation_trigger = {
+    rotation = %q
+  }
+}`, rotation)
+}
@@ -0,0 +1,334 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+var (
+	_ resource.Resource                   = &ContextSchemaResource{}
+	_ resource.ResourceWithModifyPlan     = &ContextSchemaResource{}
+	_ resource.ResourceWithValidateConfig = &ContextSchemaResource{}
+	_ resource.ResourceWithImportState    = &ContextSchemaResource{}
+)
+
+func NewContextSchemaResource() resource.Resource {
+	return &ContextSchemaResource{}
+}
+
+type ContextSchemaResource struct {
+	c *config
+}
+
+type (
+	contextSchemaResource struct {
+		ID          types.String                     `tfsdk:"id"`
+		Key         types.String                     `tfsdk:"key"`
+		Name        types.String                     `tfsdk:"name"`
+		Description types.String                     `tfsdk:"description"`
+		Attribute   []contextSchemaResourceAttribute `tfsdk:"attribute"`
+		Definition  types.String                     `tfsdk:"definition"`
+		UpdatedAt   types.String                     `tfsdk:"updated_at"`
+	}
+
+	contextSchemaResourceAttribute struct {
+		Name        types.String `tfsdk:"name"`
+		Type        types.String `tfsdk:"type"`
+		Description types.String `tfsdk:"description"`
+	}
+)
+
+var contextAttributeNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (s *ContextSchemaResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_context_schema"
+}
+
+func (s *ContextSchemaResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Wings context schema resource. Context schemas declare the attributes of the evaluation context " +
+			"and their types. Values that reference a schema with `context_schema` have their expressions and tests " +
+			"type-checked against it at plan time.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The key of this Context Schema.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Description: "The key of this Context Schema. Changing it replaces the Context Schema.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(segmentKeyPattern, "must only contain letters, digits, '-' and '_'"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The display name of this Context Schema.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"definition": schema.StringAttribute{
+				Description: "The attributes of this Context Schema and their types as JSON, to use in the `context_schema` of values.",
+				Computed:    true,
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "The time this Context Schema was last updated, in RFC 3339 format.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"attribute": schema.ListNestedBlock{
+				Description: "An attribute of the evaluation context.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The name expressions and tests refer to the attribute by.",
+							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(contextAttributeNamePattern, "must be an identifier"),
+							},
+						},
+						"type": schema.StringAttribute{
+							Description: "The type of the attribute: `string`, `int`, `double`, `bool`, `timestamp` or `list`.",
+							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(contextTypes...),
+							},
+						},
+						"description": schema.StringAttribute{
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *ContextSchemaResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var attributes []contextSchemaResourceAttribute
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("attribute"), &attributes)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	seen := map[string]bool{}
+	for i, a := range attributes {
+		if a.Name.IsUnknown() || a.Name.IsNull() {
+			continue
+		}
+		name := a.Name.ValueString()
+		if seen[name] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("attribute").AtListIndex(i).AtName("name"),
+				"Duplicate context attribute",
+				fmt.Sprintf("Attribute %q is declared more than once.", name),
+			)
+		}
+		seen[name] = true
+	}
+}
+
+func (s *ContextSchemaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || s.c == nil {
+		return
+	}
+	if req.State.Raw.IsNull() {
+		s.c.requireCapability(ctx, capabilityContextSchemas, "context schemas", path.Root("key"), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// The definition is known at plan time, so that values are type-checked
+	// against the planned attributes.
+	var plan contextSchemaResource
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !plan.known() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("definition"), contextSchemaDefinitionValue(plan.schema()))...)
+}
+
+// known reports whether the key and attributes of the schema, and so its
+// definition, are known.
+func (s *contextSchemaResource) known() bool {
+	if s.Key.IsUnknown() {
+		return false
+	}
+	for _, a := range s.Attribute {
+		if a.Name.IsUnknown() || a.Type.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *contextSchemaResource) schema() *model.ContextSchema {
+	schema := &model.ContextSchema{
+		Key:         s.Key.ValueString(),
+		Name:        s.Name.ValueString(),
+		Description: s.Description.ValueString(),
+		Attributes:  []model.ContextAttribute{},
+	}
+	for _, a := range s.Attribute {
+		schema.Attributes = append(schema.Attributes, model.ContextAttribute{
+			Name:        a.Name.ValueString(),
+			Type:        a.Type.ValueString(),
+			Description: a.Description.ValueString(),
+		})
+	}
+	return schema
+}
+
+func contextSchemaState(schema *model.ContextSchema) *contextSchemaResource {
+	state := &contextSchemaResource{
+		ID:          types.StringValue(schema.Key),
+		Key:         types.StringValue(schema.Key),
+		Name:        types.StringValue(schema.Name),
+		Description: optionalString(schema.Description),
+		Attribute:   []contextSchemaResourceAttribute{},
+		Definition:  contextSchemaDefinitionValue(schema),
+		UpdatedAt:   timeValue(schema.UpdatedAt),
+	}
+	for _, a := range schema.Attributes {
+		state.Attribute = append(state.Attribute, contextSchemaResourceAttribute{
+			Name:        types.StringValue(a.Name),
+			Type:        types.StringValue(a.Type),
+			Description: optionalString(a.Description),
+		})
+	}
+	return state
+}
+
+// contextSchemaDefinitionValue returns the definition of schema. Descriptions
+// do not affect type checks, so they are not part of it.
+func contextSchemaDefinitionValue(schema *model.ContextSchema) types.String {
+	d := &contextSchemaDefinition{Key: schema.Key, Attributes: map[string]string{}}
+	for _, a := range schema.Attributes {
+		d.Attributes[a.Name] = a.Type
+	}
+	b, _ := json.Marshal(d)
+	return types.StringValue(string(b))
+}
+
+func (s *ContextSchemaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan contextSchemaResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	schema, err := s.c.CreateContextSchema(ctx, plan.schema())
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating context schema", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(schema.Key)
+	plan.Definition = contextSchemaDefinitionValue(plan.schema())
+	plan.UpdatedAt = timeValue(schema.UpdatedAt)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *ContextSchemaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state contextSchemaResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	schema, err := s.c.GetContextSchema(ctx, state.Key.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading context schema", err.Error())
+		return
+	}
+
+	diags = resp.State.Set(ctx, contextSchemaState(schema))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *ContextSchemaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan contextSchemaResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	schema, err := s.c.UpdateContextSchema(ctx, plan.schema())
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating context schema", err.Error())
+		return
+	}
+
+	plan.Definition = contextSchemaDefinitionValue(plan.schema())
+	plan.UpdatedAt = timeValue(schema.UpdatedAt)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *ContextSchemaResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state contextSchemaResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := s.c.DeleteContextSchema(ctx, state.Key.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error deleting context schema", err.Error())
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (s *ContextSchemaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	schema, err := s.c.GetContextSchema(ctx, req.ID)
+	if isNotFound(err) {
+		resp.Diagnostics.AddError("Cannot import non-existent context schema", fmt.Sprintf("Context schema %q does not exist.", req.ID))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading context schema", err.Error())
+		return
+	}
+
+	diags := resp.State.Set(ctx, contextSchemaState(schema))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *ContextSchemaResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	s.c = req.ProviderData.(*config)
+}
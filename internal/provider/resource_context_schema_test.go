@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/jarcoal/httpmock"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+func TestAccResourceWingsContextSchema(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		schema *model.ContextSchema
+	)
+	storeSchema := func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		schema = new(model.ContextSchema)
+		if err := json.NewDecoder(req.Body).Decode(schema); err != nil {
+			return nil, err
+		}
+		return httpmock.NewJsonResponse(200, schema)
+	}
+
+	values := &testValueServer{}
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, `{"version":"1.5.0","capabilities":["context_schemas"]}`),
+	)
+	mock.RegisterResponder(http.MethodPost, "http://localhost:8018/context-schemas", storeSchema)
+	mock.RegisterResponder(http.MethodPut, "http://localhost:8018/context-schemas/default", storeSchema)
+	mock.RegisterResponder(http.MethodGet, "http://localhost:8018/context-schemas/default", func(*http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return httpmock.NewJsonResponse(200, schema)
+	})
+	mock.RegisterResponder(http.MethodDelete, "http://localhost:8018/context-schemas/default", httpmock.NewStringResponder(204, ""))
+	values.registerCreate(mock, "http://localhost:8018/values")
+	values.register(mock, "http://localhost:8018/values/checkout")
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceContextSchema("count > 1", `{"env": "test", "count": 2}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_context_schema.default", "id", "default"),
+					resource.TestCheckResourceAttr("wings_context_schema.default", "definition",
+						`{"key":"default","attributes":{"count":"int","env":"string"}}`),
+					resource.TestCheckResourceAttrPair("wings_value.checkout", "context_schema", "wings_context_schema.default", "definition"),
+				),
+			},
+			{
+				ResourceName:      "wings_context_schema.default",
+				ImportState:       true,
+				ImportStateId:     "default",
+				ImportStateVerify: true,
+			},
+			{
+				Config:      providerConfig + testAccResourceContextSchema("count == '1'", `{"env": "test", "count": 2}`),
+				ExpectError: regexp.MustCompile(`count is an int, but is compared with a string`),
+			},
+			{
+				Config:      providerConfig + testAccResourceContextSchema("count > 1", `{"env": "test", "count": "2"}`),
+				ExpectError: regexp.MustCompile(`count is an int, but is set to a string`),
+			},
+		},
+	})
+}
+
+func testAccResourceContextSchema(expr, variables string) string {
+	return fmt.Sprintf(`
+resource "wings_context_schema" "default" {
+  key  = "default"
+  name = "Default context"
+
+  attribute {
+    name = "env"
+    type = "string"
+  }
+  attribute {
+    name        = "count"
+    type        = "int"
+    description = "The number of items in the cart."
+  }
+}
+
+resource "wings_value" "checkout" {
+  value_id        = "checkout"
+  enabled         = true
+  default_variant = "off"
+  context_schema  = wings_context_schema.default.definition
+
+  bool {
+    variant = "on"
+    value   = true
+  }
+  bool {
+    variant = "off"
+    value   = false
+  }
+
+  targeting {
+    variant = "on"
+    expr    = %q
+  }
+
+  test {
+    variables = %q
+    expected  = "on"
+  }
+}`, expr, variables)
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+var (
+	_ resource.Resource                = &EnvironmentResource{}
+	_ resource.ResourceWithModifyPlan  = &EnvironmentResource{}
+	_ resource.ResourceWithImportState = &EnvironmentResource{}
+)
+
+func NewEnvironmentResource() resource.Resource {
+	return &EnvironmentResource{}
+}
+
+type EnvironmentResource struct {
+	c *config
+}
+
+type environmentResource struct {
+	ID          types.String `tfsdk:"id"`
+	Project     types.String `tfsdk:"project"`
+	Key         types.String `tfsdk:"key"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Ordering    types.Int64  `tfsdk:"ordering"`
+}
+
+func (e *EnvironmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_environment"
+}
+
+func (e *EnvironmentResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Wings environment resource. Environments, such as `staging` or `prod`, hold independent copies of the values of their project.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The project and key of this Environment, as `project/key`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"project": schema.StringAttribute{
+				Description: "The key of the project of this Environment. Changing it replaces the Environment.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Description: "The key of this Environment, used in the `environment` of values. Changing it replaces the Environment.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The display name of this Environment.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"ordering": schema.Int64Attribute{
+				Description: "The position of this Environment in listings, such as promotion order. Assigned by the server if not set.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (e *EnvironmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || e.c == nil {
+		return
+	}
+	e.c.requireCapability(ctx, capabilityProjects, "environments", path.Root("project"), &resp.Diagnostics)
+}
+
+func (e *environmentResource) environment() *model.Environment {
+	return &model.Environment{
+		Project:     e.Project.ValueString(),
+		Key:         e.Key.ValueString(),
+		Name:        e.Name.ValueString(),
+		Description: e.Description.ValueString(),
+		Ordering:    knownInt64Pointer(e.Ordering),
+	}
+}
+
+// environmentState returns the state of env, which belongs to project. The
+// project is passed separately, as it is part of the URL env was read from.
+func environmentState(project string, env *model.Environment) *environmentResource {
+	return &environmentResource{
+		ID:          types.StringValue(environmentID(project, env.Key)),
+		Project:     types.StringValue(project),
+		Key:         types.StringValue(env.Key),
+		Name:        types.StringValue(env.Name),
+		Description: optionalString(env.Description),
+		Ordering:    types.Int64PointerValue(env.Ordering),
+	}
+}
+
+func environmentID(project, key string) string {
+	return project + "/" + key
+}
+
+func (e *EnvironmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan environmentResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	env, err := e.c.CreateEnvironment(ctx, plan.environment())
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating environment", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(environmentID(plan.Project.ValueString(), env.Key))
+	addRewrittenWarning(&resp.Diagnostics, "Environment", plan.ID.ValueString(), namingChanges(plan.Name, plan.Description, env.Name, env.Description))
+	plan.Ordering = types.Int64PointerValue(env.Ordering)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (e *EnvironmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state environmentResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	env, err := e.c.GetEnvironment(ctx, state.Project.ValueString(), state.Key.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading environment", err.Error())
+		return
+	}
+
+	diags = resp.State.Set(ctx, environmentState(state.Project.ValueString(), env))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (e *EnvironmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan environmentResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	env, err := e.c.UpdateEnvironment(ctx, plan.environment())
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating environment", err.Error())
+		return
+	}
+
+	addRewrittenWarning(&resp.Diagnostics, "Environment", plan.ID.ValueString(), namingChanges(plan.Name, plan.Description, env.Name, env.Description))
+	plan.Ordering = types.Int64PointerValue(env.Ordering)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (e *EnvironmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state environmentResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := e.c.DeleteEnvironment(ctx, state.Project.ValueString(), state.Key.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error deleting environment", err.Error())
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (e *EnvironmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	project, key, ok := strings.Cut(req.ID, "/")
+	if !ok || project == "" || key == "" || strings.Contains(key, "/") {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Import ID %q must have the form project/key.", req.ID))
+		return
+	}
+
+	env, err := e.c.GetEnvironment(ctx, project, key)
+	if isNotFound(err) {
+		resp.Diagnostics.AddError("Cannot import non-existent environment", fmt.Sprintf("Environment %q does not exist.", req.ID))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading environment", err.Error())
+		return
+	}
+
+	diags := resp.State.Set(ctx, environmentState(project, env))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (e *EnvironmentResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	e.c = req.ProviderData.(*config)
+}
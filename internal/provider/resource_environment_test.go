@@ -0,0 +1,105 @@
+package provider
+
+import (
+	_ "embed"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/jarcoal/httpmock"
+)
+
+var (
+	//go:embed testdata/environment.json
+	environmentTestdata string
+	//go:embed testdata/environment_drifted.json
+	environmentDriftedTestdata string
+)
+
+func TestAccResourceWingsEnvironment(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, capabilitiesTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodPost,
+		"http://localhost:8018/projects/payments/environments",
+		withoutOrdering(t, environmentTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/projects/payments/environments/staging",
+		httpmock.NewStringResponder(200, environmentTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodPut,
+		"http://localhost:8018/projects/payments/environments/staging",
+		withoutOrdering(t, environmentTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		"http://localhost:8018/projects/payments/environments/staging",
+		httpmock.NewStringResponder(204, ""),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceEnvironment(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_environment.staging", "id", "payments/staging"),
+					resource.TestCheckResourceAttr("wings_environment.staging", "project", "payments"),
+					resource.TestCheckResourceAttr("wings_environment.staging", "key", "staging"),
+					resource.TestCheckResourceAttr("wings_environment.staging", "name", "Staging"),
+					resource.TestCheckNoResourceAttr("wings_environment.staging", "description"),
+					resource.TestCheckResourceAttr("wings_environment.staging", "ordering", "2"),
+				),
+			},
+			{
+				ResourceName:      "wings_environment.staging",
+				ImportState:       true,
+				ImportStateId:     "payments/staging",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:  "wings_environment.staging",
+				ImportState:   true,
+				ImportStateId: "staging",
+				ExpectError:   regexp.MustCompile("Invalid import ID"),
+			},
+			{
+				// Changes made outside of Terraform show up as drift.
+				PreConfig: func() {
+					mock.RegisterResponder(
+						http.MethodGet,
+						"http://localhost:8018/projects/payments/environments/staging",
+						httpmock.NewStringResponder(200, environmentDriftedTestdata),
+					)
+				},
+				Config:             providerConfig + testAccResourceEnvironment(),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccResourceEnvironment() string {
+	return `
+resource "wings_environment" "staging" {
+  project = "payments"
+  key     = "staging"
+  name    = "Staging"
+}`
+}
@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+var (
+	_ resource.Resource                = &ProjectResource{}
+	_ resource.ResourceWithModifyPlan  = &ProjectResource{}
+	_ resource.ResourceWithImportState = &ProjectResource{}
+)
+
+func NewProjectResource() resource.Resource {
+	return &ProjectResource{}
+}
+
+type ProjectResource struct {
+	c *config
+}
+
+type projectResource struct {
+	ID          types.String `tfsdk:"id"`
+	Key         types.String `tfsdk:"key"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Ordering    types.Int64  `tfsdk:"ordering"`
+}
+
+func (p *ProjectResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_project"
+}
+
+func (p *ProjectResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Wings project resource. Projects group values, and hold the environments values are managed in.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The key of this Project.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Description: "The key of this Project, used in the `project` of values. Changing it replaces the Project.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The display name of this Project.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"ordering": schema.Int64Attribute{
+				Description: "The position of this Project in listings. Assigned by the server if not set.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (p *ProjectResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || p.c == nil {
+		return
+	}
+	p.c.requireCapability(ctx, capabilityProjects, "projects", path.Root("key"), &resp.Diagnostics)
+}
+
+func (p *projectResource) project() *model.Project {
+	return &model.Project{
+		Key:         p.Key.ValueString(),
+		Name:        p.Name.ValueString(),
+		Description: p.Description.ValueString(),
+		Ordering:    knownInt64Pointer(p.Ordering),
+	}
+}
+
+// knownInt64Pointer returns a pointer to the value of v, or nil if v is null
+// or unknown, so that attributes the server assigns are omitted from writes.
+func knownInt64Pointer(v types.Int64) *int64 {
+	if v.IsNull() || v.IsUnknown() {
+		return nil
+	}
+	return v.ValueInt64Pointer()
+}
+
+// namingChanges returns how the name and description the server stored differ
+// from the planned ones, in the form reconcileValue reports changes in.
+func namingChanges(name, description types.String, gotName, gotDescription string) []string {
+	r := &valueReconciler{}
+	r.string("name", name, types.StringValue(gotName), stringEquivalent)
+	r.string("description", description, optionalString(gotDescription), stringEquivalent)
+	return r.changes
+}
+
+func projectState(project *model.Project) *projectResource {
+	return &projectResource{
+		ID:          types.StringValue(project.Key),
+		Key:         types.StringValue(project.Key),
+		Name:        types.StringValue(project.Name),
+		Description: optionalString(project.Description),
+		Ordering:    types.Int64PointerValue(project.Ordering),
+	}
+}
+
+func (p *ProjectResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan projectResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	project, err := p.c.CreateProject(ctx, plan.project())
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating project", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(project.Key)
+	addRewrittenWarning(&resp.Diagnostics, "Project", project.Key, namingChanges(plan.Name, plan.Description, project.Name, project.Description))
+	plan.Ordering = types.Int64PointerValue(project.Ordering)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (p *ProjectResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state projectResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	project, err := p.c.GetProject(ctx, state.Key.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading project", err.Error())
+		return
+	}
+
+	diags = resp.State.Set(ctx, projectState(project))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (p *ProjectResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan projectResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	project, err := p.c.UpdateProject(ctx, plan.project())
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating project", err.Error())
+		return
+	}
+
+	addRewrittenWarning(&resp.Diagnostics, "Project", project.Key, namingChanges(plan.Name, plan.Description, project.Name, project.Description))
+	plan.Ordering = types.Int64PointerValue(project.Ordering)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (p *ProjectResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state projectResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := p.c.DeleteProject(ctx, state.Key.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error deleting project", err.Error())
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (p *ProjectResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	project, err := p.c.GetProject(ctx, req.ID)
+	if isNotFound(err) {
+		resp.Diagnostics.AddError("Cannot import non-existent project", fmt.Sprintf("Project %q does not exist.", req.ID))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading project", err.Error())
+		return
+	}
+
+	diags := resp.State.Set(ctx, projectState(project))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (p *ProjectResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	p.c = req.ProviderData.(*config)
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/jarcoal/httpmock"
+)
+
+var (
+	//go:embed testdata/project.json
+	projectTestdata string
+	//go:embed testdata/project_drifted.json
+	projectDriftedTestdata string
+)
+
+const capabilitiesTestdata = `{"version":"1.4.0","capabilities":["projects"]}`
+
+func TestAccResourceWingsProject(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, capabilitiesTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodPost,
+		"http://localhost:8018/projects",
+		withoutOrdering(t, projectTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/projects/payments",
+		httpmock.NewStringResponder(200, projectTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodPut,
+		"http://localhost:8018/projects/payments",
+		withoutOrdering(t, projectTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		"http://localhost:8018/projects/payments",
+		httpmock.NewStringResponder(204, ""),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceProject(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_project.payments", "id", "payments"),
+					resource.TestCheckResourceAttr("wings_project.payments", "key", "payments"),
+					resource.TestCheckResourceAttr("wings_project.payments", "name", "Payments"),
+					resource.TestCheckResourceAttr("wings_project.payments", "description", "Checkout and billing flags"),
+					resource.TestCheckResourceAttr("wings_project.payments", "ordering", "1"),
+				),
+			},
+			{
+				ResourceName:      "wings_project.payments",
+				ImportState:       true,
+				ImportStateId:     "payments",
+				ImportStateVerify: true,
+			},
+			{
+				// Changes made outside of Terraform show up as drift.
+				PreConfig: func() {
+					mock.RegisterResponder(
+						http.MethodGet,
+						"http://localhost:8018/projects/payments",
+						httpmock.NewStringResponder(200, projectDriftedTestdata),
+					)
+				},
+				Config:             providerConfig + testAccResourceProject(),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// withoutOrdering responds with body to requests that leave the ordering to
+// the server, as configurations without ordering must.
+func withoutOrdering(t *testing.T, body string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		var sent map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&sent); err != nil {
+			return nil, err
+		}
+		if ordering, ok := sent["ordering"]; ok {
+			t.Errorf("%s %s sent ordering %v, want it omitted", req.Method, req.URL, ordering)
+		}
+		return httpmock.NewStringResponse(200, body), nil
+	}
+}
+
+func Test_ProjectOrderingUnknown(t *testing.T) {
+	project := (&projectResource{
+		Key:      types.StringValue("payments"),
+		Ordering: types.Int64Unknown(),
+	}).project()
+	if project.Ordering != nil {
+		t.Errorf("ordering = %d, want nil", *project.Ordering)
+	}
+
+	project = (&projectResource{Ordering: types.Int64Value(3)}).project()
+	if project.Ordering == nil || *project.Ordering != 3 {
+		t.Errorf("ordering = %v, want 3", project.Ordering)
+	}
+}
+
+func Test_NamingChanges(t *testing.T) {
+	tests := []struct {
+		name, description       types.String
+		gotName, gotDescription string
+		want                    []string
+	}{
+		{name: types.StringValue("Payments"), description: types.StringNull(), gotName: "Payments"},
+		{name: types.StringValue("Payments"), description: types.StringValue("Checkout"), gotName: "Payments", gotDescription: "Checkout"},
+		{
+			name: types.StringValue(" Payments "), description: types.StringValue("Checkout"), gotName: "Payments",
+			want: []string{`name: " Payments " -> "Payments"`, `description: "Checkout" -> <null>`},
+		},
+	}
+	for _, tt := range tests {
+		got := namingChanges(tt.name, tt.description, tt.gotName, tt.gotDescription)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("namingChanges(%s, %s, %q, %q) = %q, want %q", tt.name, tt.description, tt.gotName, tt.gotDescription, got, tt.want)
+		}
+	}
+}
+
+func TestAccResourceWingsProject_UnsupportedServer(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(404, "404 page not found"),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccResourceProject(),
+				ExpectError: regexp.MustCompile(`does not support projects`),
+			},
+		},
+	})
+}
+
+func testAccResourceProject() string {
+	return `
+resource "wings_project" "payments" {
+  key         = "payments"
+  name        = "Payments"
+  description = "Checkout and billing flags"
+}`
+}
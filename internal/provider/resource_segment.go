@@ -0,0 +1,341 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// How the rules of a segment are combined.
+const (
+	segmentMatchAll = "all"
+	segmentMatchAny = "any"
+)
+
+var (
+	_ resource.Resource                = &SegmentResource{}
+	_ resource.ResourceWithModifyPlan  = &SegmentResource{}
+	_ resource.ResourceWithImportState = &SegmentResource{}
+)
+
+func NewSegmentResource() resource.Resource {
+	return &SegmentResource{}
+}
+
+type SegmentResource struct {
+	c *config
+}
+
+type segmentResource struct {
+	ID          types.String   `tfsdk:"id"`
+	Key         types.String   `tfsdk:"key"`
+	Name        types.String   `tfsdk:"name"`
+	Description types.String   `tfsdk:"description"`
+	Expr        types.String   `tfsdk:"expr"`
+	Rules       []types.String `tfsdk:"rules"`
+	Match       types.String   `tfsdk:"match"`
+	Ref         types.String   `tfsdk:"ref"`
+	UpdatedAt   types.String   `tfsdk:"updated_at"`
+}
+
+func (s *SegmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_segment"
+}
+
+func (s *SegmentResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Wings segment resource. Segments name an audience, such as internal employees, " +
+			"that the `targeting` rules of values reference with `segment` instead of repeating its expression.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The key of this Segment.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Description: "The key of this Segment. Changing it replaces the Segment.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(segmentKeyPattern, "must only contain letters, digits, '-' and '_'"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The display name of this Segment.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"expr": schema.StringAttribute{
+				Description: "The expression that matches the members of this Segment. Conflicts with `rules`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("rules")),
+				},
+			},
+			"rules": schema.ListAttribute{
+				Description: "Expressions that match the members of this Segment, combined according to `match`. Conflicts with `expr`.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"match": schema.StringAttribute{
+				Description: "Whether `all` (default) or `any` of `rules` must match.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(segmentMatchAll),
+				Validators: []validator.String{
+					stringvalidator.OneOf(segmentMatchAll, segmentMatchAny),
+				},
+			},
+			"ref": schema.StringAttribute{
+				Description: "The reference to use in the `segment` of targeting rules. It includes a hash of the definition of this Segment, " +
+					"so that changes to the Segment show up in the plans of the values that reference it.",
+				Computed: true,
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "The time this Segment was last updated, in RFC 3339 format.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (s *SegmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || s.c == nil {
+		return
+	}
+	if req.State.Raw.IsNull() {
+		s.c.requireCapability(ctx, capabilitySegments, "segments", path.Root("key"), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var plan segmentResource
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !plan.known() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ref"), segmentRef(plan.segment()))...)
+}
+
+// known reports whether the definition of the segment, and so its ref, is
+// known.
+func (s *segmentResource) known() bool {
+	if s.Key.IsUnknown() || s.Expr.IsUnknown() || s.Match.IsUnknown() {
+		return false
+	}
+	for _, r := range s.Rules {
+		if r.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *segmentResource) segment() *model.Segment {
+	segment := &model.Segment{
+		Key:         s.Key.ValueString(),
+		Name:        s.Name.ValueString(),
+		Description: s.Description.ValueString(),
+		Expr:        s.Expr.ValueString(),
+		Match:       s.Match.ValueString(),
+	}
+	for _, r := range s.Rules {
+		segment.Rules = append(segment.Rules, r.ValueString())
+	}
+	return segment
+}
+
+func segmentState(segment *model.Segment) *segmentResource {
+	state := &segmentResource{
+		ID:          types.StringValue(segment.Key),
+		Key:         types.StringValue(segment.Key),
+		Name:        types.StringValue(segment.Name),
+		Description: optionalString(segment.Description),
+		Expr:        optionalString(segment.Expr),
+		Match:       types.StringValue(segment.Match),
+		Ref:         segmentRef(segment),
+		UpdatedAt:   timeValue(segment.UpdatedAt),
+	}
+	if state.Match.ValueString() == "" {
+		state.Match = types.StringValue(segmentMatchAll)
+	}
+	for _, r := range segment.Rules {
+		state.Rules = append(state.Rules, types.StringValue(r))
+	}
+	return state
+}
+
+var segmentKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// segmentRef returns the ref of segment: its key and a hash of its
+// definition, as key@hash. The name and description do not affect who is a
+// member, so they are not part of the hash, and neither is the formatting of
+// expressions.
+func segmentRef(segment *model.Segment) types.String {
+	match := segment.Match
+	if match == "" {
+		match = segmentMatchAll
+	}
+	rules := make([]string, 0, len(segment.Rules))
+	for _, r := range segment.Rules {
+		rules = append(rules, normalizeExpr(r))
+	}
+	b, _ := json.Marshal(struct {
+		Expr  string   `json:"expr"`
+		Rules []string `json:"rules"`
+		Match string   `json:"match"`
+	}{normalizeExpr(segment.Expr), rules, match})
+	sum := sha256.Sum256(b)
+	return types.StringValue(segment.Key + "@" + hex.EncodeToString(sum[:6]))
+}
+
+// segmentKey returns the key of the segment of ref, which may be a key or a
+// ref with a hash.
+func segmentKey(ref string) string {
+	key, _, _ := strings.Cut(ref, "@")
+	return key
+}
+
+// segmentRefEquivalent reports whether a and b reference the same segment.
+// The server only knows segments by key, so the hash of a ref is ignored.
+func segmentRefEquivalent(a, b string) bool {
+	return segmentKey(a) == segmentKey(b)
+}
+
+func (s *SegmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan segmentResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	segment, err := s.c.CreateSegment(ctx, plan.segment())
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating segment", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(segment.Key)
+	plan.Ref = segmentRef(plan.segment())
+	plan.UpdatedAt = timeValue(segment.UpdatedAt)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *SegmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state segmentResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	segment, err := s.c.GetSegment(ctx, state.Key.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading segment", err.Error())
+		return
+	}
+
+	// Keep the configured formatting of expressions the server reformatted.
+	refreshed := segmentState(segment)
+	if exprEquivalent(state.Expr.ValueString(), refreshed.Expr.ValueString()) {
+		refreshed.Expr = state.Expr
+	}
+	for i := range min(len(state.Rules), len(refreshed.Rules)) {
+		if exprEquivalent(state.Rules[i].ValueString(), refreshed.Rules[i].ValueString()) {
+			refreshed.Rules[i] = state.Rules[i]
+		}
+	}
+	diags = resp.State.Set(ctx, refreshed)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *SegmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan segmentResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	segment, err := s.c.UpdateSegment(ctx, plan.segment())
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating segment", err.Error())
+		return
+	}
+
+	plan.Ref = segmentRef(plan.segment())
+	plan.UpdatedAt = timeValue(segment.UpdatedAt)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *SegmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state segmentResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := s.c.DeleteSegment(ctx, state.Key.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error deleting segment", err.Error())
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (s *SegmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	segment, err := s.c.GetSegment(ctx, req.ID)
+	if isNotFound(err) {
+		resp.Diagnostics.AddError("Cannot import non-existent segment", fmt.Sprintf("Segment %q does not exist.", req.ID))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading segment", err.Error())
+		return
+	}
+
+	diags := resp.State.Set(ctx, segmentState(segment))
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *SegmentResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	s.c = req.ProviderData.(*config)
+}
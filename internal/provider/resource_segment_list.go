@@ -0,0 +1,476 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// segmentListChunkSize is the most members added or removed per request.
+const segmentListChunkSize = 5000
+
+var (
+	_ resource.Resource                = &SegmentListResource{}
+	_ resource.ResourceWithModifyPlan  = &SegmentListResource{}
+	_ resource.ResourceWithImportState = &SegmentListResource{}
+)
+
+func NewSegmentListResource() resource.Resource {
+	return &SegmentListResource{}
+}
+
+type SegmentListResource struct {
+	c *config
+}
+
+type segmentListResource struct {
+	ID                types.String `tfsdk:"id"`
+	Key               types.String `tfsdk:"key"`
+	Name              types.String `tfsdk:"name"`
+	Description       types.String `tfsdk:"description"`
+	Members           types.Set    `tfsdk:"members"`
+	MembersFile       types.String `tfsdk:"members_file"`
+	MembersFileHeader types.Bool   `tfsdk:"members_file_header"`
+	MemberCount       types.Int64  `tfsdk:"member_count"`
+	MembersHash       types.String `tfsdk:"members_hash"`
+	Ref               types.String `tfsdk:"ref"`
+	UpdatedAt         types.String `tfsdk:"updated_at"`
+}
+
+func (s *SegmentListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_segment_list"
+}
+
+func (s *SegmentListResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Wings segment list resource. Segment lists are segments defined by an explicit list of members, " +
+			"such as the user IDs of a beta program, that the `targeting` rules of values reference with `segment_list`. " +
+			"Members are uploaded in chunks, and updates only send the members that were added or removed.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The key of this Segment List.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Description: "The key of this Segment List. Changing it replaces the Segment List.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(segmentKeyPattern, "must only contain letters, digits, '-' and '_'"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The display name of this Segment List.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"members": schema.SetAttribute{
+				Description: "The members of this Segment List. Conflicts with `members_file`. The list is empty if neither is set.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"members_file": schema.StringAttribute{
+				Description: "Path to a file of the members of this Segment List. Files ending in `.csv` are read as CSV, " +
+					"with members in the first column. Other files have a member per line, and lines that are empty or start with `#` are ignored. " +
+					"Conflicts with `members`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("members")),
+				},
+			},
+			"members_file_header": schema.BoolAttribute{
+				Description: "Whether the first row of a CSV `members_file` is a header to skip.",
+				Optional:    true,
+			},
+			"member_count": schema.Int64Attribute{
+				Description: "The number of members of this Segment List.",
+				Computed:    true,
+			},
+			"members_hash": schema.StringAttribute{
+				Description: "A hash of the members of this Segment List, which changes when members are added or removed, " +
+					"including outside of Terraform.",
+				Computed: true,
+			},
+			"ref": schema.StringAttribute{
+				Description: "The reference to use in the `segment_list` of targeting rules. It includes `members_hash`, " +
+					"so that changes to the members show up in the plans of the values that reference it.",
+				Computed: true,
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "The time this Segment List was last updated, in RFC 3339 format.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (s *SegmentListResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || s.c == nil {
+		return
+	}
+	if req.State.Raw.IsNull() {
+		s.c.requireCapability(ctx, capabilitySegmentLists, "segment lists", path.Root("key"), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var plan segmentListResource
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !plan.known() {
+		return
+	}
+
+	// Reading the members at plan time shows changes to a members_file in
+	// the plan, and in the plans of the values that reference the list.
+	members, err := plan.members()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("members_file"), "Unable to read segment list members", err.Error())
+		return
+	}
+	hash := membersHash(members)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("member_count"), types.Int64Value(int64(len(members))))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("members_hash"), hash)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ref"), segmentListRef(plan.Key.ValueString(), hash))...)
+}
+
+// known reports whether the members of the segment list are known.
+func (s *segmentListResource) known() bool {
+	if s.Key.IsUnknown() || s.MembersFile.IsUnknown() || s.MembersFileHeader.IsUnknown() || s.Members.IsUnknown() {
+		return false
+	}
+	for _, m := range s.Members.Elements() {
+		if m.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// members returns the configured members, sorted and without duplicates.
+func (s *segmentListResource) members() ([]string, error) {
+	if !s.MembersFile.IsNull() {
+		return loadSegmentListMembers(s.MembersFile.ValueString(), s.MembersFileHeader.ValueBool())
+	}
+	members := make([]string, 0, len(s.Members.Elements()))
+	for _, m := range s.Members.Elements() {
+		if m, ok := m.(types.String); ok {
+			members = append(members, m.ValueString())
+		}
+	}
+	return normalizeMembers(members), nil
+}
+
+func (s *segmentListResource) segmentList() *model.SegmentList {
+	return &model.SegmentList{
+		Key:         s.Key.ValueString(),
+		Name:        s.Name.ValueString(),
+		Description: s.Description.ValueString(),
+	}
+}
+
+// loadSegmentListMembers reads the members of a segment list from the file
+// at name, which is CSV if it ends in .csv and has a member per line
+// otherwise.
+func loadSegmentListMembers(name string, header bool) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var members []string
+	if strings.EqualFold(filepath.Ext(name), ".csv") {
+		r := csv.NewReader(f)
+		r.FieldsPerRecord = -1
+		for first := true; ; first = false {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if first && header {
+				continue
+			}
+			members = append(members, record[0])
+		}
+	} else {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			members = append(members, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return normalizeMembers(members), nil
+}
+
+// normalizeMembers trims the members, and sorts them without duplicates and
+// empty members.
+func normalizeMembers(members []string) []string {
+	normalized := make([]string, 0, len(members))
+	for _, m := range members {
+		if m = strings.TrimSpace(m); m != "" {
+			normalized = append(normalized, m)
+		}
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// membersHash returns the hash of members, which must be normalized.
+func membersHash(members []string) types.String {
+	h := sha256.New()
+	for _, m := range members {
+		h.Write([]byte(m))
+		h.Write([]byte{'\n'})
+	}
+	return types.StringValue(hex.EncodeToString(h.Sum(nil)))
+}
+
+func segmentListRef(key string, hash types.String) types.String {
+	return types.StringValue(key + "@" + hash.ValueString()[:12])
+}
+
+// diffMembers returns the members of want missing from have, and the members
+// of have missing from want. Both must be normalized.
+func diffMembers(have, want []string) *model.SegmentListChanges {
+	changes := &model.SegmentListChanges{}
+	i, j := 0, 0
+	for i < len(have) || j < len(want) {
+		switch {
+		case j == len(want) || i < len(have) && have[i] < want[j]:
+			changes.Remove = append(changes.Remove, have[i])
+			i++
+		case i == len(have) || want[j] < have[i]:
+			changes.Add = append(changes.Add, want[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return changes
+}
+
+// syncMembers changes the members of the segment list key to members, which
+// must be normalized, sending the additions and removals in chunks.
+func (s *SegmentListResource) syncMembers(ctx context.Context, key string, members []string) error {
+	current, err := s.c.ListSegmentListMembers(ctx, key)
+	if err != nil {
+		return err
+	}
+	changes := diffMembers(normalizeMembers(current), members)
+	tflog.Debug(ctx, "Changing Wings segment list members", map[string]any{
+		"segment_list": key,
+		"added":        len(changes.Add),
+		"removed":      len(changes.Remove),
+	})
+
+	for chunk := range slices.Chunk(changes.Remove, segmentListChunkSize) {
+		if err := s.c.ChangeSegmentListMembers(ctx, key, &model.SegmentListChanges{Remove: chunk}); err != nil {
+			return err
+		}
+	}
+	for chunk := range slices.Chunk(changes.Add, segmentListChunkSize) {
+		if err := s.c.ChangeSegmentListMembers(ctx, key, &model.SegmentListChanges{Add: chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMembers reads the configured members of plan and syncs them to the
+// server. The members must be the ones the plan was computed from.
+func (s *SegmentListResource) applyMembers(ctx context.Context, plan *segmentListResource) error {
+	members, err := plan.members()
+	if err != nil {
+		return err
+	}
+	if hash := membersHash(members); !plan.MembersHash.IsUnknown() && !plan.MembersHash.Equal(hash) {
+		return fmt.Errorf("the members changed after the plan was made; plan again")
+	}
+	if err := s.syncMembers(ctx, plan.Key.ValueString(), members); err != nil {
+		return err
+	}
+	plan.MemberCount = types.Int64Value(int64(len(members)))
+	plan.MembersHash = membersHash(members)
+	plan.Ref = segmentListRef(plan.Key.ValueString(), plan.MembersHash)
+	return nil
+}
+
+func (s *SegmentListResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan segmentListResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	list, err := s.c.CreateSegmentList(ctx, plan.segmentList())
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating segment list", err.Error())
+		return
+	}
+	plan.ID = types.StringValue(list.Key)
+	plan.UpdatedAt = timeValue(list.UpdatedAt)
+
+	if err := s.applyMembers(ctx, &plan); err != nil {
+		// The list exists, so keep it in state to be completed or destroyed.
+		resp.Diagnostics.AddError("Error uploading segment list members", err.Error())
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), plan.ID)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), plan.Key)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("member_count"), types.Int64Null())...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("members_hash"), types.StringNull())...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ref"), types.StringNull())...)
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *SegmentListResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state segmentListResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	list, err := s.c.GetSegmentList(ctx, state.Key.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading segment list", err.Error())
+		return
+	}
+	members, err := s.c.ListSegmentListMembers(ctx, state.Key.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading segment list members", err.Error())
+		return
+	}
+
+	// The members themselves are not refreshed, as they can be many. Changes
+	// made outside of Terraform show up as a different members_hash.
+	members = normalizeMembers(members)
+	state.ID = types.StringValue(list.Key)
+	state.Name = types.StringValue(list.Name)
+	state.Description = optionalString(list.Description)
+	state.MemberCount = types.Int64Value(int64(len(members)))
+	state.MembersHash = membersHash(members)
+	state.Ref = segmentListRef(list.Key, state.MembersHash)
+	state.UpdatedAt = timeValue(list.UpdatedAt)
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *SegmentListResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state segmentListResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.UpdatedAt = state.UpdatedAt
+	if !plan.Name.Equal(state.Name) || !plan.Description.Equal(state.Description) {
+		list, err := s.c.UpdateSegmentList(ctx, plan.segmentList())
+		if err != nil {
+			resp.Diagnostics.AddError("Error updating segment list", err.Error())
+			return
+		}
+		plan.UpdatedAt = timeValue(list.UpdatedAt)
+	}
+
+	if !plan.MembersHash.Equal(state.MembersHash) {
+		if err := s.applyMembers(ctx, &plan); err != nil {
+			resp.Diagnostics.AddError("Error uploading segment list members", err.Error())
+			return
+		}
+		list, err := s.c.GetSegmentList(ctx, plan.Key.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Error reading segment list", err.Error())
+			return
+		}
+		plan.UpdatedAt = timeValue(list.UpdatedAt)
+	}
+
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (s *SegmentListResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state segmentListResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := s.c.DeleteSegmentList(ctx, state.Key.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error deleting segment list", err.Error())
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (s *SegmentListResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	_, err := s.c.GetSegmentList(ctx, req.ID)
+	if isNotFound(err) {
+		resp.Diagnostics.AddError("Cannot import non-existent segment list", fmt.Sprintf("Segment list %q does not exist.", req.ID))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading segment list", err.Error())
+		return
+	}
+
+	// The remaining attributes are read by Read, which follows the import.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+func (s *SegmentListResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	s.c = req.ProviderData.(*config)
+}
@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/jarcoal/httpmock"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// testSegmentListServer stores the members of the segment list beta, and
+// records the changes made to them.
+type testSegmentListServer struct {
+	mu      sync.Mutex
+	members map[string]bool
+	changes []*model.SegmentListChanges
+}
+
+func (s *testSegmentListServer) register(mock *httpmock.MockTransport) {
+	const u = "http://localhost:8018/segment-lists/beta"
+	list := func(*http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(200, `{"key":"beta","name":"Beta testers","description":""}`), nil
+	}
+	mock.RegisterResponder(http.MethodPost, "http://localhost:8018/segment-lists", list)
+	mock.RegisterResponder(http.MethodGet, u, list)
+	mock.RegisterResponder(http.MethodPut, u, list)
+	mock.RegisterResponder(http.MethodDelete, u, httpmock.NewStringResponder(204, ""))
+
+	// Members are returned in pages of 1000.
+	mock.RegisterResponder(http.MethodGet, u+"/members", func(req *http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		members := slices.Sorted(func(yield func(string) bool) {
+			for m := range s.members {
+				if !yield(m) {
+					return
+				}
+			}
+		})
+		start, _ := strconv.Atoi(req.URL.Query().Get("cursor"))
+		end := min(start+1000, len(members))
+		page := &model.SegmentListMembers{Members: members[start:end]}
+		if end < len(members) {
+			page.NextCursor = strconv.Itoa(end)
+		}
+		return httpmock.NewJsonResponse(200, page)
+	})
+	mock.RegisterResponder(http.MethodPost, u+"/members", func(req *http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		changes := new(model.SegmentListChanges)
+		if err := json.NewDecoder(req.Body).Decode(changes); err != nil {
+			return nil, err
+		}
+		if s.members == nil {
+			s.members = map[string]bool{}
+		}
+		for _, m := range changes.Add {
+			s.members[m] = true
+		}
+		for _, m := range changes.Remove {
+			delete(s.members, m)
+		}
+		s.changes = append(s.changes, changes)
+		return httpmock.NewStringResponse(204, ""), nil
+	})
+}
+
+func TestAccResourceWingsSegmentList(t *testing.T) {
+	server := &testSegmentListServer{}
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, `{"version":"1.5.0","capabilities":["segment_lists"]}`),
+	)
+	server.register(mock)
+	values := &testValueServer{}
+	values.registerCreate(mock, "http://localhost:8018/values")
+	values.register(mock, "http://localhost:8018/values/checkout")
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	file := filepath.Join(t.TempDir(), "beta.csv")
+	writeMembers := func(members ...string) {
+		data := "user_id,email\n"
+		for _, m := range members {
+			data += m + "," + m + "@example.com\n"
+		}
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeMembers("u1", "u2", "u3")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceSegmentList(file),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_segment_list.beta", "id", "beta"),
+					resource.TestCheckResourceAttr("wings_segment_list.beta", "member_count", "3"),
+					resource.TestCheckResourceAttrPair("wings_value.checkout", "targeting.0.segment_list", "wings_segment_list.beta", "ref"),
+				),
+			},
+			{
+				ResourceName:            "wings_segment_list.beta",
+				ImportState:             true,
+				ImportStateId:           "beta",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"members_file", "members_file_header"},
+			},
+			{
+				// Changes to the file show up in the plan, also of the values
+				// that reference the list.
+				PreConfig: func() { writeMembers("u2", "u3", "u4") },
+				Config:    providerConfig + testAccResourceSegmentList(file),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("wings_segment_list.beta", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("wings_value.checkout", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("wings_segment_list.beta", "member_count", "3"),
+			},
+			{
+				// Members changed outside of Terraform show up as drift.
+				PreConfig: func() {
+					server.mu.Lock()
+					defer server.mu.Unlock()
+					server.members["u9"] = true
+				},
+				Config:             providerConfig + testAccResourceSegmentList(file),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func Test_SegmentListSyncMembers(t *testing.T) {
+	server := &testSegmentListServer{}
+	mock := httpmock.NewMockTransport()
+	server.register(mock)
+	s := &SegmentListResource{c: &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}}
+
+	members := make([]string, 0, 12000)
+	for i := range 12000 {
+		members = append(members, fmt.Sprintf("user-%05d", i))
+	}
+	if err := s.syncMembers(context.Background(), "beta", members); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.changes) != 3 {
+		t.Errorf("uploaded %d chunks, want 3", len(server.changes))
+	}
+
+	// Only the difference is sent on updates.
+	server.changes = nil
+	updated := append(slices.Clone(members[1:]), "user-99999")
+	if err := s.syncMembers(context.Background(), "beta", updated); err != nil {
+		t.Fatal(err)
+	}
+	want := []*model.SegmentListChanges{
+		{Remove: []string{"user-00000"}},
+		{Add: []string{"user-99999"}},
+	}
+	got, _ := json.Marshal(server.changes)
+	if w, _ := json.Marshal(want); string(got) != string(w) {
+		t.Errorf("changes = %s, want %s", got, w)
+	}
+	if len(server.members) != 12000 {
+		t.Errorf("server has %d members, want 12000", len(server.members))
+	}
+}
+
+func Test_LoadSegmentListMembers(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		data   string
+		header bool
+		want   []string
+	}{
+		{
+			name: "members.txt",
+			data: "# Beta testers\nu2\n\n  u1  \nu2\n",
+			want: []string{"u1", "u2"},
+		},
+		{
+			name:   "members.csv",
+			data:   "user_id,email\nu2,b@example.com\nu1,a@example.com\n",
+			header: true,
+			want:   []string{"u1", "u2"},
+		},
+		{
+			name: "headless.csv",
+			data: "u1\nu3,extra\n",
+			want: []string{"u1", "u3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(name, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadSegmentListMembers(name, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("loadSegmentListMembers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := loadSegmentListMembers(filepath.Join(dir, "missing.txt"), false); err == nil {
+		t.Error("loadSegmentListMembers() of a missing file succeeded")
+	}
+}
+
+func Test_SegmentListMembersUnset(t *testing.T) {
+	list := &segmentListResource{
+		Members:           types.SetNull(types.StringType),
+		MembersFile:       types.StringNull(),
+		MembersFileHeader: types.BoolNull(),
+	}
+	got, err := list.members()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("members() = %#v, want an empty list", got)
+	}
+}
+
+func Test_DiffMembers(t *testing.T) {
+	changes := diffMembers([]string{"a", "b", "d"}, []string{"b", "c", "d", "e"})
+	if !slices.Equal(changes.Add, []string{"c", "e"}) || !slices.Equal(changes.Remove, []string{"a"}) {
+		t.Errorf("diffMembers() = %+v, want add [c e] and remove [a]", changes)
+	}
+}
+
+func testAccResourceSegmentList(file string) string {
+	return fmt.Sprintf(`
+resource "wings_segment_list" "beta" {
+  key                 = "beta"
+  name                = "Beta testers"
+  members_file        = %q
+  members_file_header = true
+}
+
+resource "wings_value" "checkout" {
+  value_id        = "checkout"
+  enabled         = true
+  default_variant = "off"
+
+  bool {
+    variant = "on"
+    value   = true
+  }
+  bool {
+    variant = "off"
+    value   = false
+  }
+
+  targeting {
+    variant      = "on"
+    segment_list = wings_segment_list.beta.ref
+  }
+}`, file)
+}
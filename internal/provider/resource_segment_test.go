@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jarcoal/httpmock"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+func TestAccResourceWingsSegment(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		segment *model.Segment
+	)
+	storeSegment := func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		segment = new(model.Segment)
+		if err := json.NewDecoder(req.Body).Decode(segment); err != nil {
+			return nil, err
+		}
+		return httpmock.NewJsonResponse(200, segment)
+	}
+
+	values := &testValueServer{}
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, `{"version":"1.5.0","capabilities":["segments"]}`),
+	)
+	mock.RegisterResponder(http.MethodPost, "http://localhost:8018/segments", storeSegment)
+	mock.RegisterResponder(http.MethodPut, "http://localhost:8018/segments/internal", storeSegment)
+	mock.RegisterResponder(http.MethodGet, "http://localhost:8018/segments/internal", func(*http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return httpmock.NewJsonResponse(200, segment)
+	})
+	mock.RegisterResponder(http.MethodDelete, "http://localhost:8018/segments/internal", httpmock.NewStringResponder(204, ""))
+	values.registerCreate(mock, "http://localhost:8018/values")
+	values.register(mock, "http://localhost:8018/values/checkout")
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceSegment("email.endsWith('@example.com')"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_segment.internal", "id", "internal"),
+					resource.TestCheckResourceAttr("wings_segment.internal", "match", "all"),
+					resource.TestCheckResourceAttrPair("wings_value.checkout", "targeting.0.segment", "wings_segment.internal", "ref"),
+					// Only the key of the segment is sent to the server.
+					func(*terraform.State) error {
+						if rules := values.rules(); len(rules) != 1 || rules[0].Segment != "internal" || rules[0].Expr != "" {
+							return fmt.Errorf("server rules = %+v, want a rule of segment internal", rules)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "wings_segment.internal",
+				ImportState:       true,
+				ImportStateId:     "internal",
+				ImportStateVerify: true,
+			},
+			{
+				// Imported values reference the segment by its ref, as the
+				// configuration does.
+				ResourceName:      "wings_value.checkout",
+				ImportState:       true,
+				ImportStateId:     "checkout",
+				ImportStateVerify: true,
+			},
+			{
+				// Changes to a segment show up in the plans of the values that
+				// reference it.
+				Config: providerConfig + testAccResourceSegment("email.endsWith('@example.org')"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("wings_segment.internal", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("wings_value.checkout", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func Test_SegmentRef(t *testing.T) {
+	base := &model.Segment{Key: "internal", Name: "Internal", Expr: "email.endsWith('@example.com')"}
+	ref := segmentRef(base).ValueString()
+
+	same := []*model.Segment{
+		{Key: "internal", Name: "Employees", Description: "Renamed", Expr: "email.endsWith('@example.com')"},
+		{Key: "internal", Expr: "email.endsWith( '@example.com' )"},
+		{Key: "internal", Expr: "email.endsWith('@example.com')", Match: segmentMatchAll},
+	}
+	for _, s := range same {
+		if got := segmentRef(s).ValueString(); got != ref {
+			t.Errorf("segmentRef(%+v) = %q, want %q", s, got, ref)
+		}
+	}
+
+	different := []*model.Segment{
+		{Key: "internal", Expr: "email.endsWith('@example.org')"},
+		{Key: "internal", Rules: []string{"email.endsWith('@example.com')"}},
+		{Key: "staff", Expr: "email.endsWith('@example.com')"},
+	}
+	for _, s := range different {
+		if got := segmentRef(s).ValueString(); got == ref {
+			t.Errorf("segmentRef(%+v) = %q, want a different ref", s, got)
+		}
+	}
+
+	if key := segmentKey(ref); key != "internal" {
+		t.Errorf("segmentKey(%q) = %q, want internal", ref, key)
+	}
+	if !segmentRefEquivalent(ref, "internal") {
+		t.Errorf("segmentRefEquivalent(%q, internal) = false, want true", ref)
+	}
+}
+
+func testAccResourceSegment(expr string) string {
+	return fmt.Sprintf(`
+resource "wings_segment" "internal" {
+  key  = "internal"
+  name = "Internal employees"
+  expr = %q
+}
+
+resource "wings_value" "checkout" {
+  value_id        = "checkout"
+  enabled         = true
+  default_variant = "off"
+
+  bool {
+    variant = "on"
+    value   = true
+  }
+  bool {
+    variant = "off"
+    value   = false
+  }
+
+  targeting {
+    variant = "on"
+    segment = wings_segment.internal.ref
+  }
+}`, expr)
+}
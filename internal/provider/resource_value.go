@@ -3,9 +3,16 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"maps"
 	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -16,8 +23,15 @@ import (
 	"fantech.dev/terraform-provider-wings/internal/model"
 )
 
+// Modes of managing the targeting rules of a value.
+const (
+	targetingModeExclusive = "exclusive"
+	targetingModeShared    = "shared"
+)
+
 var (
-	_ resource.Resource = &ValueResource{}
+	_ resource.Resource               = &ValueResource{}
+	_ resource.ResourceWithModifyPlan = &ValueResource{}
 )
 
 func NewValueResource() resource.Resource {
@@ -30,17 +44,27 @@ type ValueResource struct {
 
 type (
 	valueResource struct {
-		ID             types.String             `tfsdk:"id"`
-		ValueID        types.String             `tfsdk:"value_id"`
-		Description    types.String             `tfsdk:"description"`
-		Enabled        types.Bool               `tfsdk:"enabled"`
-		DefaultVariant types.String             `tfsdk:"default_variant"`
-		Bool           []valueResourceBool      `tfsdk:"bool"`
-		Int            []valueResourceInt       `tfsdk:"int"`
-		String         []valueResourceString    `tfsdk:"string"`
-		Object         []valueResourceObject    `tfsdk:"object"`
-		Targeting      []valueResourceTargeting `tfsdk:"targeting"`
-		Test           []valueResourceTest      `tfsdk:"test"`
+		ID             types.String                `tfsdk:"id"`
+		ValueID        types.String                `tfsdk:"value_id"`
+		Project        types.String                `tfsdk:"project"`
+		Environment    types.String                `tfsdk:"environment"`
+		Description    types.String                `tfsdk:"description"`
+		Enabled        types.Bool                  `tfsdk:"enabled"`
+		DefaultVariant types.String                `tfsdk:"default_variant"`
+		AdoptExisting  types.Bool                  `tfsdk:"adopt_existing"`
+		TargetingMode  types.String                `tfsdk:"targeting_mode"`
+		ContextSchema  types.String                `tfsdk:"context_schema"`
+		Bool           []valueResourceBool         `tfsdk:"bool"`
+		Int            []valueResourceInt          `tfsdk:"int"`
+		String         []valueResourceString       `tfsdk:"string"`
+		Object         []valueResourceObject       `tfsdk:"object"`
+		Targeting      []valueResourceTargeting    `tfsdk:"targeting"`
+		Test           []valueResourceTest         `tfsdk:"test"`
+		Prerequisite   []valueResourcePrerequisite `tfsdk:"prerequisite"`
+		CreatedAt      types.String                `tfsdk:"created_at"`
+		UpdatedAt      types.String                `tfsdk:"updated_at"`
+		UpdatedBy      types.String                `tfsdk:"updated_by"`
+		Revision       types.Int64                 `tfsdk:"revision"`
 	}
 
 	valueResourceBool struct {
@@ -65,8 +89,10 @@ type (
 	}
 
 	valueResourceTargeting struct {
-		Variant types.String `tfsdk:"variant"`
-		Expr    types.String `tfsdk:"expr"`
+		Variant     types.String `tfsdk:"variant"`
+		Expr        types.String `tfsdk:"expr"`
+		Segment     types.String `tfsdk:"segment"`
+		SegmentList types.String `tfsdk:"segment_list"`
 	}
 
 	valueResourceTest struct {
@@ -77,20 +103,83 @@ type (
 	valueResourceTransform struct {
 		Expr types.String `tfsdk:"expr"`
 	}
+
+	valueResourcePrerequisite struct {
+		ValueID types.String `tfsdk:"value_id"`
+		Variant types.String `tfsdk:"variant"`
+	}
 )
 
 func (v *ValueResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	value, err := v.c.GetValue(ctx, req.ID)
+	id, err := parseValueImportID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+	// Bare value IDs are in the provider's default scope, as values configured
+	// without a project and environment are.
+	scope := model.Scope{Project: id.Project, Environment: id.Environment}
+	if scope.IsZero() {
+		scope = v.c.scope
+	}
+
+	value, err := v.c.GetValue(ctx, scope, id.ValueID)
+	if isNotFound(err) {
+		resp.Diagnostics.AddError("Cannot import non-existent value", fmt.Sprintf("Value %q does not exist.", req.ID))
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Error get value", err.Error())
+		resp.Diagnostics.AddError("Error reading value", err.Error())
 		return
 	}
+	value.Project, value.Environment = scope.Project, scope.Environment
 
+	// There is no prior state to reconcile with, so segments are referenced
+	// by their current refs, which configurations use.
 	state := valueState(value)
-	diags := resp.State.Set(ctx, &state)
+	if err := v.c.resolveTargetingRefs(ctx, state.Targeting); err != nil {
+		resp.Diagnostics.AddError("Error reading value", err.Error())
+		return
+	}
+	diags := resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 }
 
+// valueImportID is the parsed form of a wings_value import ID, which is either
+// a bare value_id or project/environment/value_id.
+type valueImportID struct {
+	Project     string
+	Environment string
+	ValueID     string
+}
+
+// scopedValueID returns the ID of the value valueID in scope, which is the
+// inverse of parseValueImportID.
+func scopedValueID(scope model.Scope, valueID string) string {
+	if scope.IsZero() {
+		return valueID
+	}
+	return strings.Join([]string{scope.Project, scope.Environment, valueID}, "/")
+}
+
+func parseValueImportID(id string) (valueImportID, error) {
+	parts := strings.Split(id, "/")
+	for _, p := range parts {
+		if p == "" {
+			return valueImportID{}, fmt.Errorf("import ID %q must not contain empty segments; expected value_id or project/environment/value_id", id)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return valueImportID{ValueID: parts[0]}, nil
+	case 3:
+		return valueImportID{Project: parts[0], Environment: parts[1], ValueID: parts[2]}, nil
+	default:
+		return valueImportID{}, fmt.Errorf("import ID %q has %d segments; expected value_id or project/environment/value_id", id, len(parts))
+	}
+}
+
 func (v *ValueResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_value"
 }
@@ -100,7 +189,7 @@ func (v *ValueResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 		MarkdownDescription: "Wings value resource.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Description: "Computed ID.",
+				Description: "The value_id, prefixed with `project/environment/` for values in a project.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
@@ -113,6 +202,16 @@ func (v *ValueResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"project": schema.StringAttribute{
+				Description: "The project of this Value. Defaults to the provider's `project`. Changing it replaces the Value.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"environment": schema.StringAttribute{
+				Description: "The environment of this Value. Defaults to the provider's `environment`. Changing it replaces the Value.",
+				Optional:    true,
+				Computed:    true,
+			},
 			"description": schema.StringAttribute{
 				Optional: true,
 			},
@@ -122,6 +221,44 @@ func (v *ValueResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 			"default_variant": schema.StringAttribute{
 				Required: true,
 			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "Adopt a value with the same ID that already exists on the server instead of failing, " +
+					"updating it to match the configuration. Defaults to the provider's `adopt_existing`.",
+				Optional: true,
+			},
+			"targeting_mode": schema.StringAttribute{
+				Description: "How the targeting rules of this Value are managed: `exclusive` (default) manages all of them, " +
+					"and removes rules added elsewhere. `shared` only manages the `targeting` blocks of this resource, " +
+					"and keeps the named rules managed by `wings_value_targeting_rule` resources.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(targetingModeExclusive, targetingModeShared),
+				},
+			},
+			"context_schema": schema.StringAttribute{
+				Description: "The `definition` of the `wings_context_schema` to type-check the `expr` of `targeting` and `transform` blocks " +
+					"and the `variables` of `test` blocks against at plan time. It is only used by the provider, and not sent to Wings.",
+				Optional: true,
+			},
+			"created_at": schema.StringAttribute{
+				Description: "The time this Value was created, in RFC 3339 format.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "The time this Value was last updated, in RFC 3339 format.",
+				Computed:    true,
+			},
+			"updated_by": schema.StringAttribute{
+				Description: "The principal that last updated this Value.",
+				Computed:    true,
+			},
+			"revision": schema.Int64Attribute{
+				Description: "The revision of this Value, incremented by the server on every update.",
+				Computed:    true,
+			},
 		},
 		Blocks: map[string]schema.Block{
 			"bool": schema.ListNestedBlock{
@@ -190,13 +327,22 @@ func (v *ValueResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				},
 			},
 			"targeting": schema.ListNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: targetingRuleAttributes(),
+				},
+			},
+			"prerequisite": schema.ListNestedBlock{
+				Description: "A value that must serve a variant for this Value to be evaluated. " +
+					"Unless all prerequisites serve their variant, this Value serves its `default_variant`.",
 				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
-						"variant": schema.StringAttribute{
-							Required: true,
+						"value_id": schema.StringAttribute{
+							Description: "The value_id of the prerequisite, which is in the same project and environment as this Value.",
+							Required:    true,
 						},
-						"expr": schema.StringAttribute{
-							Required: true,
+						"variant": schema.StringAttribute{
+							Description: "The variant the prerequisite must serve.",
+							Required:    true,
 						},
 					},
 				},
@@ -217,6 +363,221 @@ func (v *ValueResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 	}
 }
 
+// targetingRuleAttributes returns the attributes of targeting blocks, which
+// match either an expression, a segment or a segment list.
+func targetingRuleAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"variant": schema.StringAttribute{
+			Required: true,
+		},
+		"expr": schema.StringAttribute{
+			Description: "The expression the rule matches with. Conflicts with `segment` and `segment_list`.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.ExactlyOneOf(
+					path.MatchRelative().AtParent().AtName("segment"),
+					path.MatchRelative().AtParent().AtName("segment_list"),
+				),
+			},
+		},
+		"segment": schema.StringAttribute{
+			Description: "The `ref` of the `wings_segment` the rule matches the members of. Conflicts with `expr` and `segment_list`.",
+			Optional:    true,
+		},
+		"segment_list": schema.StringAttribute{
+			Description: "The `ref` of the `wings_segment_list` the rule matches the members of. Conflicts with `expr` and `segment`.",
+			Optional:    true,
+		},
+	}
+}
+
+func (v *ValueResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if v.c == nil {
+		return
+	}
+	if req.Plan.Raw.IsNull() {
+		// Values that are about to be destroyed no longer satisfy or depend
+		// on the prerequisites of others.
+		var state valueResource
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if !resp.Diagnostics.HasError() {
+			v.c.planned.remove(scopedValueID(state.scope(), state.ValueID.ValueString()))
+		}
+		return
+	}
+
+	v.planScope(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var targetingMode types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("targeting_mode"), &targetingMode)...)
+	if targetingMode.ValueString() == targetingModeShared {
+		v.c.requireCapability(ctx, capabilityNamedTargetingRules, "shared targeting rules", path.Root("targeting_mode"), &resp.Diagnostics)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	v.checkContext(ctx, resp)
+	v.checkPrerequisites(ctx, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if v.c.collisionCheck == "" || v.c.collisionCheck == collisionCheckOff {
+		return
+	}
+
+	var plan valueResource
+	diags := resp.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || plan.ValueID.IsUnknown() || plan.Project.IsUnknown() || plan.Environment.IsUnknown() {
+		return
+	}
+
+	// Only values that are about to be created can collide with existing
+	// ones. Besides new values, these are the replacements of values whose
+	// ID or scope changed.
+	id := plan.ValueID.ValueString()
+	if !req.State.Raw.IsNull() {
+		var state valueResource
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() || scopedValueID(state.scope(), state.ValueID.ValueString()) == scopedValueID(plan.scope(), id) {
+			return
+		}
+	}
+
+	_, err := v.c.GetValue(ctx, plan.scope(), id)
+	if isNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddWarning("Unable to check value ID", fmt.Sprintf("Checking whether value %q already exists failed: %s", id, err))
+		return
+	}
+
+	if v.adoptExisting(&plan) {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("value_id"),
+			"Value already exists",
+			fmt.Sprintf("Value %q already exists on the server and will be adopted and updated to match the configuration.", id),
+		)
+		return
+	}
+
+	summary := "Value already exists"
+	detail := fmt.Sprintf("Value %q already exists on the server, so creating it will fail. "+
+		"Import it, set adopt_existing, or choose a different value_id.", id)
+	if v.c.collisionCheck == collisionCheckError {
+		resp.Diagnostics.AddAttributeError(path.Root("value_id"), summary, detail)
+		return
+	}
+	resp.Diagnostics.AddAttributeWarning(path.Root("value_id"), summary, detail)
+}
+
+// checkContext type-checks the expressions and tests of the planned value
+// against its context_schema.
+func (v *ValueResource) checkContext(ctx context.Context, resp *resource.ModifyPlanResponse) {
+	var plan valueResource
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.ContextSchema.IsNull() || plan.ContextSchema.IsUnknown() {
+		return
+	}
+
+	definition, err := parseContextSchemaDefinition(plan.ContextSchema.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("context_schema"), "Invalid context schema", err.Error())
+		return
+	}
+
+	check := func(p path.Path, attr types.String, check func(string) []string) {
+		if attr.IsNull() || attr.IsUnknown() {
+			return
+		}
+		for _, problem := range check(attr.ValueString()) {
+			resp.Diagnostics.AddAttributeError(p, "Context type mismatch", problem+".")
+		}
+	}
+	for i, t := range plan.Targeting {
+		check(path.Root("targeting").AtListIndex(i).AtName("expr"), t.Expr, func(expr string) []string {
+			return definition.checkExpr(expr)
+		})
+	}
+	for i, o := range plan.Object {
+		// Transforms also refer to the fields of the object they transform.
+		var fields map[string]any
+		if o.Value.IsUnknown() || json.Unmarshal([]byte(o.Value.ValueString()), &fields) != nil {
+			continue
+		}
+		for j, t := range o.Transform {
+			check(path.Root("object").AtListIndex(i).AtName("transform").AtListIndex(j).AtName("expr"), t.Expr, func(expr string) []string {
+				return definition.checkExpr(expr, slices.Collect(maps.Keys(fields))...)
+			})
+		}
+	}
+	for i, t := range plan.Test {
+		check(path.Root("test").AtListIndex(i).AtName("variables"), t.Variables, definition.checkVariables)
+	}
+}
+
+// planScope plans project and environment from the configuration, falling
+// back to the provider's defaults, and replaces values whose scope changed.
+func (v *ValueResource) planScope(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var configured, state valueResource
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("project"), &configured.Project)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("environment"), &configured.Environment)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	project := scopeAttribute(configured.Project, v.c.scope.Project)
+	environment := scopeAttribute(configured.Environment, v.c.scope.Environment)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("project"), project)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("environment"), environment)...)
+	if project.IsUnknown() || environment.IsUnknown() {
+		return
+	}
+
+	if project.IsNull() != environment.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("project"),
+			"Incomplete value scope",
+			"Values in a project also need an environment, and values in an environment also need a project. "+
+				"Set both project and environment, on the resource or on the provider, or neither.",
+		)
+		return
+	}
+	if !project.IsNull() {
+		v.c.requireCapability(ctx, capabilityProjects, "projects and environments", path.Root("project"), &resp.Diagnostics)
+	}
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if !state.Project.Equal(project) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("project"))
+	}
+	if !state.Environment.Equal(environment) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("environment"))
+	}
+}
+
+// scopeAttribute returns the configured project or environment, or the
+// provider's default if it is not configured.
+func scopeAttribute(configured types.String, providerDefault string) types.String {
+	if !configured.IsNull() {
+		return configured
+	}
+	return optionalString(providerDefault)
+}
+
+func (v *valueResource) scope() model.Scope {
+	return model.Scope{Project: v.Project.ValueString(), Environment: v.Environment.ValueString()}
+}
+
 func (v *valueResource) value() (*model.Value, error) {
 	variants := model.Variants{}
 	for _, val := range v.Bool {
@@ -262,10 +623,7 @@ func (v *valueResource) value() (*model.Value, error) {
 
 	rules := make([]model.ValueTargetingRule, 0, len(v.Targeting))
 	for _, t := range v.Targeting {
-		rules = append(rules, model.ValueTargetingRule{
-			Variant: t.Variant.ValueString(),
-			Expr:    t.Expr.ValueString(),
-		})
+		rules = append(rules, t.rule())
 	}
 
 	tests := make([]*model.EvaluationTest, 0, len(v.Test))
@@ -280,6 +638,11 @@ func (v *valueResource) value() (*model.Value, error) {
 			Expected:  t.Expected.ValueString(),
 		})
 	}
+	prerequisites := make([]model.ValuePrerequisite, 0, len(v.Prerequisite))
+	for _, p := range v.Prerequisite {
+		prerequisites = append(prerequisites, p.prerequisite())
+	}
+
 	value := &model.Value{
 		ID:             v.ValueID.ValueString(),
 		Enabled:        v.Enabled.ValueBool(),
@@ -289,42 +652,41 @@ func (v *valueResource) value() (*model.Value, error) {
 		Targeting: model.Targeting{
 			Rules: rules,
 		},
-		Tests: tests,
+		Tests:         tests,
+		Prerequisites: prerequisites,
+		Project:       v.Project.ValueString(),
+		Environment:   v.Environment.ValueString(),
 	}
 	return value, nil
 }
 
 func valueState(v *model.Value) *valueResource {
+	// Absent blocks are represented as empty lists, as Terraform does for
+	// configuration, so that imported state matches idiomatic configuration.
 	var (
-		bools   []valueResourceBool
-		strs    []valueResourceString
-		objects []valueResourceObject
-		ints    []valueResourceInt
+		bools   = []valueResourceBool{}
+		strs    = []valueResourceString{}
+		objects = []valueResourceObject{}
+		ints    = []valueResourceInt{}
 	)
 
-	for k, val := range v.Variants {
+	// Variants are a map on the wire, so order them by name to keep the
+	// state stable between runs.
+	for _, k := range slices.Sorted(maps.Keys(v.Variants)) {
+		val := v.Variants[k]
 		if val.Bool != nil {
-			if bools == nil {
-				bools = make([]valueResourceBool, 0, len(v.Variants))
-			}
 			bools = append(bools, valueResourceBool{
 				Variant: types.StringValue(k),
 				Value:   types.BoolValue(val.Bool.Value),
 			})
 		}
 		if val.String != nil {
-			if strs == nil {
-				strs = make([]valueResourceString, 0, len(v.Variants))
-			}
 			strs = append(strs, valueResourceString{
 				Variant: types.StringValue(k),
 				Value:   types.StringValue(val.String.Value),
 			})
 		}
 		if val.Object != nil {
-			if objects == nil {
-				objects = make([]valueResourceObject, 0, len(v.Variants))
-			}
 			b, _ := json.Marshal(val.Object.Value)
 			transforms := make([]valueResourceTransform, 0, len(val.Object.Transforms))
 			for _, t := range val.Object.Transforms {
@@ -339,9 +701,6 @@ func valueState(v *model.Value) *valueResource {
 			})
 		}
 		if val.Int != nil {
-			if ints == nil {
-				ints = make([]valueResourceInt, 0, len(v.Variants))
-			}
 			ints = append(ints, valueResourceInt{
 				Variant: types.StringValue(k),
 				Value:   types.Int64Value(val.Int.Value),
@@ -351,10 +710,7 @@ func valueState(v *model.Value) *valueResource {
 
 	targeting := make([]valueResourceTargeting, 0, len(v.Targeting.Rules))
 	for _, t := range v.Targeting.Rules {
-		targeting = append(targeting, valueResourceTargeting{
-			Variant: types.StringValue(t.Variant),
-			Expr:    types.StringValue(t.Expr),
-		})
+		targeting = append(targeting, targetingState(t))
 	}
 
 	tests := make([]valueResourceTest, 0, len(v.Tests))
@@ -366,10 +722,19 @@ func valueState(v *model.Value) *valueResource {
 		})
 	}
 
-	return &valueResource{
-		ID:             types.StringValue(v.ID),
+	prerequisites := make([]valueResourcePrerequisite, 0, len(v.Prerequisites))
+	for _, p := range v.Prerequisites {
+		prerequisites = append(prerequisites, valueResourcePrerequisite{
+			ValueID: types.StringValue(p.ValueID),
+			Variant: types.StringValue(p.Variant),
+		})
+	}
+
+	state := &valueResource{
 		ValueID:        types.StringValue(v.ID),
-		Description:    types.StringValue(v.Description),
+		Project:        optionalString(v.Project),
+		Environment:    optionalString(v.Environment),
+		Description:    optionalString(v.Description),
 		Enabled:        types.BoolValue(v.Enabled),
 		DefaultVariant: types.StringValue(v.DefaultVariant),
 		Bool:           bools,
@@ -378,7 +743,86 @@ func valueState(v *model.Value) *valueResource {
 		Int:            ints,
 		Targeting:      targeting,
 		Test:           tests,
+		Prerequisite:   prerequisites,
 	}
+	state.setMetadata(v)
+	return state
+}
+
+func (t *valueResourceTargeting) rule() model.ValueTargetingRule {
+	return model.ValueTargetingRule{
+		Variant:     t.Variant.ValueString(),
+		Expr:        t.Expr.ValueString(),
+		Segment:     segmentKey(t.Segment.ValueString()),
+		SegmentList: segmentKey(t.SegmentList.ValueString()),
+	}
+}
+
+func (p *valueResourcePrerequisite) prerequisite() model.ValuePrerequisite {
+	return model.ValuePrerequisite{
+		ValueID: p.ValueID.ValueString(),
+		Variant: p.Variant.ValueString(),
+	}
+}
+
+func targetingState(rule model.ValueTargetingRule) valueResourceTargeting {
+	return valueResourceTargeting{
+		Variant:     types.StringValue(rule.Variant),
+		Expr:        optionalString(rule.Expr),
+		Segment:     optionalString(rule.Segment),
+		SegmentList: optionalString(rule.SegmentList),
+	}
+}
+
+// resolveTargetingRefs replaces the bare keys of the segments and segment
+// lists that targeting references with their current refs.
+func (c *config) resolveTargetingRefs(ctx context.Context, targeting []valueResourceTargeting) error {
+	for i, t := range targeting {
+		if key := t.Segment.ValueString(); key != "" && key == segmentKey(key) {
+			segment, err := c.GetSegment(ctx, key)
+			if err != nil {
+				return fmt.Errorf("reading segment %q: %w", key, err)
+			}
+			targeting[i].Segment = segmentRef(segment)
+		}
+		if key := t.SegmentList.ValueString(); key != "" && key == segmentKey(key) {
+			members, err := c.ListSegmentListMembers(ctx, key)
+			if err != nil {
+				return fmt.Errorf("reading segment list %q: %w", key, err)
+			}
+			targeting[i].SegmentList = segmentListRef(key, membersHash(normalizeMembers(members)))
+		}
+	}
+	return nil
+}
+
+// setMetadata copies the server-maintained attributes of value into the
+// resource, whose scope must already be set.
+func (v *valueResource) setMetadata(value *model.Value) {
+	v.ID = types.StringValue(scopedValueID(v.scope(), value.ID))
+	v.CreatedAt = timeValue(value.CreatedAt)
+	v.UpdatedAt = timeValue(value.UpdatedAt)
+	v.UpdatedBy = optionalString(value.UpdatedBy)
+	v.Revision = types.Int64Null()
+	if value.Revision != 0 {
+		v.Revision = types.Int64Value(value.Revision)
+	}
+}
+
+// optionalString maps the empty string the server reports for an omitted
+// attribute to null.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
+
+func timeValue(t *time.Time) types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+	return types.StringValue(t.Format(time.RFC3339))
 }
 
 func (v *ValueResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -395,13 +839,21 @@ func (v *ValueResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	value, err = v.c.CreateValue(ctx, value)
+	created, err := v.c.CreateValue(ctx, value)
+	if isConflict(err) && v.adoptExisting(&plan) {
+		created, err = v.adopt(ctx, &plan, value, &resp.Diagnostics)
+	}
 	if err != nil {
 		resp.Diagnostics.AddError("Error creating value", err.Error())
 		return
 	}
+	value = plan.owned(created)
 
-	plan.ID = types.StringValue(value.ID)
+	// Terraform requires the applied state to match the plan, so rewrites are
+	// only reported here and show up as drift on the next refresh.
+	_, changes := reconcileValue(&plan, value)
+	addRewrittenWarning(&resp.Diagnostics, "Value", plan.ValueID.ValueString(), changes)
+	plan.setMetadata(value)
 	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 }
@@ -414,7 +866,18 @@ func (v *ValueResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	diags = resp.State.Set(ctx, &state)
+	value, err := v.c.GetValue(ctx, state.scope(), state.ValueID.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading value", err.Error())
+		return
+	}
+
+	refreshed, _ := reconcileValue(&state, state.owned(value))
+	diags = resp.State.Set(ctx, refreshed)
 	resp.Diagnostics.Append(diags...)
 }
 
@@ -432,11 +895,16 @@ func (v *ValueResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	_, err = v.c.UpdateValue(ctx, value)
+	value, err = v.update(ctx, &plan, value)
 	if err != nil {
 		resp.Diagnostics.AddError("Error updating value", err.Error())
 		return
 	}
+	value = plan.owned(value)
+
+	_, changes := reconcileValue(&plan, value)
+	addRewrittenWarning(&resp.Diagnostics, "Value", plan.ValueID.ValueString(), changes)
+	plan.setMetadata(value)
 
 	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -450,7 +918,7 @@ func (v *ValueResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	err := v.c.DeleteValue(ctx, state.ID.ValueString())
+	err := v.c.DeleteValue(ctx, state.scope(), state.ValueID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("Error deleting value", err.Error())
 		return
@@ -459,6 +927,71 @@ func (v *ValueResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	resp.State.RemoveResource(ctx)
 }
 
+func (v *ValueResource) adoptExisting(plan *valueResource) bool {
+	if plan.AdoptExisting.IsNull() {
+		return v.c.adoptExisting
+	}
+	return plan.AdoptExisting.ValueBool()
+}
+
+// adopt takes over a value that already exists on the server by updating it
+// to match the plan.
+func (v *ValueResource) adopt(ctx context.Context, plan *valueResource, value *model.Value, diags *diag.Diagnostics) (*model.Value, error) {
+	existing, err := v.c.GetValue(ctx, value.Scope(), value.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := fmt.Sprintf("Value %q already exists and was updated to match the configuration.", value.ID)
+	if _, changes := reconcileValue(plan, existing); len(changes) > 0 {
+		detail += fmt.Sprintf(" Differences from the existing value (configured -> existing):\n\n  - %s", strings.Join(changes, "\n  - "))
+	} else {
+		detail += " It already matched the configuration."
+	}
+	diags.AddWarning("Adopted existing value", detail)
+
+	return v.update(ctx, plan, value)
+}
+
+// update updates the value of plan on the server. In the shared targeting
+// mode, the named rules of the current value are kept.
+func (v *ValueResource) update(ctx context.Context, plan *valueResource, value *model.Value) (*model.Value, error) {
+	if plan.TargetingMode.ValueString() != targetingModeShared {
+		return v.c.UpdateValue(ctx, value)
+	}
+	own := value.Targeting.Rules
+	return v.c.ModifyValue(ctx, value.Scope(), value.ID, func(current *model.Value) (*model.Value, error) {
+		rules := append(slices.Clone(own), namedTargetingRules(current.Targeting.Rules)...)
+		value.Targeting.Rules = orderTargetingRules(rules)
+		return value, nil
+	})
+}
+
+// owned returns remote without the parts of it that v does not manage, which
+// are the named targeting rules in the shared targeting mode.
+func (v *valueResource) owned(remote *model.Value) *model.Value {
+	if v.TargetingMode.ValueString() != targetingModeShared {
+		return remote
+	}
+	owned := *remote
+	owned.Targeting.Rules = ownTargetingRules(remote.Targeting.Rules)
+	return &owned
+}
+
+// addRewrittenWarning warns that the Wings API stored the object of kind,
+// such as "Value", with the given changes to its configuration.
+func addRewrittenWarning(diags *diag.Diagnostics, kind, id string, changes []string) {
+	if len(changes) == 0 {
+		return
+	}
+	diags.AddWarning(
+		kind+" rewritten by Wings",
+		fmt.Sprintf("The Wings API stored %s %q differently than configured:\n\n  - %s\n\n", strings.ToLower(kind), id, strings.Join(changes, "\n  - "))+
+			"The configured values were kept in state, so the next refresh will report the server's values as drift. "+
+			"Update the configuration to match them to avoid a perpetual diff.",
+	)
+}
+
 func (v *ValueResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
@@ -0,0 +1,311 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+var (
+	_ resource.Resource                   = &ValueEnvironmentOverrideResource{}
+	_ resource.ResourceWithModifyPlan     = &ValueEnvironmentOverrideResource{}
+	_ resource.ResourceWithImportState    = &ValueEnvironmentOverrideResource{}
+	_ resource.ResourceWithValidateConfig = &ValueEnvironmentOverrideResource{}
+)
+
+func NewValueEnvironmentOverrideResource() resource.Resource {
+	return &ValueEnvironmentOverrideResource{}
+}
+
+type ValueEnvironmentOverrideResource struct {
+	c *config
+}
+
+type valueOverrideResource struct {
+	ID             types.String             `tfsdk:"id"`
+	Value          types.String             `tfsdk:"value"`
+	Environment    types.String             `tfsdk:"environment"`
+	Enabled        types.Bool               `tfsdk:"enabled"`
+	DefaultVariant types.String             `tfsdk:"default_variant"`
+	Targeting      []valueResourceTargeting `tfsdk:"targeting"`
+	UpdatedAt      types.String             `tfsdk:"updated_at"`
+	UpdatedBy      types.String             `tfsdk:"updated_by"`
+}
+
+func (o *ValueEnvironmentOverrideResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_value_environment_override"
+}
+
+func (o *ValueEnvironmentOverrideResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Wings value environment override resource. Layers settings for one environment onto a base `wings_value`, " +
+			"which keeps its own definition. Settings that are not set are inherited from the base value, " +
+			"so the base value and its overrides can be managed from separate configurations.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The base value and environment of this Override, as `project/environment/value_id/override_environment`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"value": schema.StringAttribute{
+				Description: "The `id` of the base `wings_value`, as `project/environment/value_id`. Changing it replaces the Override.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"environment": schema.StringAttribute{
+				Description: "The environment of the base value's project this Override applies to. Changing it replaces the Override.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Overrides `enabled` of the base value.",
+				Optional:    true,
+			},
+			"default_variant": schema.StringAttribute{
+				Description: "Overrides `default_variant` of the base value.",
+				Optional:    true,
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "The time this Override was last updated, in RFC 3339 format.",
+				Computed:    true,
+			},
+			"updated_by": schema.StringAttribute{
+				Description: "The principal that last updated this Override.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"targeting": schema.ListNestedBlock{
+				Description: "Replaces the targeting rules of the base value. Without any `targeting` blocks, the rules of the base value are inherited.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: targetingRuleAttributes(),
+				},
+			},
+		},
+	}
+}
+
+func (o *ValueEnvironmentOverrideResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config valueOverrideResource
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("value"), &config.Value)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("environment"), &config.Environment)...)
+	if resp.Diagnostics.HasError() || config.Value.IsUnknown() || config.Value.IsNull() {
+		return
+	}
+
+	base, err := parseValueImportID(config.Value.ValueString())
+	if err == nil && base.Project == "" {
+		err = fmt.Errorf("value %q is not in a project; overrides apply to values in a project and environment", config.Value.ValueString())
+	}
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("value"), "Invalid base value", err.Error())
+		return
+	}
+	if config.Environment.ValueString() == base.Environment {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("environment"),
+			"Override of the base environment",
+			fmt.Sprintf("Value %q is defined in environment %q, so it cannot be overridden there. Change the base value instead.",
+				config.Value.ValueString(), base.Environment),
+		)
+	}
+}
+
+func (o *ValueEnvironmentOverrideResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || o.c == nil {
+		return
+	}
+	o.c.requireCapability(ctx, capabilityEnvironmentOverrides, "environment overrides", path.Root("environment"), &resp.Diagnostics)
+}
+
+// base returns the scope and value_id of the base value.
+func (o *valueOverrideResource) base() (model.Scope, string) {
+	// The format of value is validated by ValidateConfig.
+	id, _ := parseValueImportID(o.Value.ValueString())
+	return model.Scope{Project: id.Project, Environment: id.Environment}, id.ValueID
+}
+
+func (o *valueOverrideResource) override() *model.ValueOverride {
+	override := &model.ValueOverride{
+		Enabled:        o.Enabled.ValueBoolPointer(),
+		DefaultVariant: o.DefaultVariant.ValueStringPointer(),
+	}
+	if len(o.Targeting) > 0 {
+		override.Targeting = &model.Targeting{Rules: make([]model.ValueTargetingRule, 0, len(o.Targeting))}
+		for _, t := range o.Targeting {
+			override.Targeting.Rules = append(override.Targeting.Rules, t.rule())
+		}
+	}
+	return override
+}
+
+func valueOverrideState(value, environment string, override *model.ValueOverride) *valueOverrideResource {
+	state := &valueOverrideResource{
+		ID:             types.StringValue(valueOverrideID(value, environment)),
+		Value:          types.StringValue(value),
+		Environment:    types.StringValue(environment),
+		Enabled:        types.BoolPointerValue(override.Enabled),
+		DefaultVariant: types.StringPointerValue(override.DefaultVariant),
+		Targeting:      []valueResourceTargeting{},
+	}
+	if override.Targeting != nil {
+		for _, t := range override.Targeting.Rules {
+			state.Targeting = append(state.Targeting, targetingState(t))
+		}
+	}
+	state.setMetadata(override)
+	return state
+}
+
+func (o *valueOverrideResource) setMetadata(override *model.ValueOverride) {
+	o.UpdatedAt = timeValue(override.UpdatedAt)
+	o.UpdatedBy = optionalString(override.UpdatedBy)
+}
+
+func valueOverrideID(value, environment string) string {
+	return value + "/" + environment
+}
+
+func (o *ValueEnvironmentOverrideResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan valueOverrideResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scope, id := plan.base()
+	override, err := o.c.PutValueOverride(ctx, scope, id, plan.Environment.ValueString(), plan.override())
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating value override", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(valueOverrideID(plan.Value.ValueString(), plan.Environment.ValueString()))
+	plan.setMetadata(override)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (o *ValueEnvironmentOverrideResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state valueOverrideResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scope, id := state.base()
+	override, err := o.c.GetValueOverride(ctx, scope, id, state.Environment.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading value override", err.Error())
+		return
+	}
+
+	// Keep the configured refs of segments and formatting of expressions,
+	// which the server reports as bare keys and may reformat.
+	refreshed := valueOverrideState(state.Value.ValueString(), state.Environment.ValueString(), override)
+	refreshed.Targeting = reconcileTargeting(&valueReconciler{}, state.Targeting, refreshed.Targeting)
+	diags = resp.State.Set(ctx, refreshed)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (o *ValueEnvironmentOverrideResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan valueOverrideResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scope, id := plan.base()
+	override, err := o.c.PutValueOverride(ctx, scope, id, plan.Environment.ValueString(), plan.override())
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating value override", err.Error())
+		return
+	}
+
+	plan.setMetadata(override)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (o *ValueEnvironmentOverrideResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state valueOverrideResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Deleting the override reverts the environment to the base value, which
+	// is left alone.
+	scope, id := state.base()
+	if err := o.c.DeleteValueOverride(ctx, scope, id, state.Environment.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Error deleting value override", err.Error())
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (o *ValueEnvironmentOverrideResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	i := strings.LastIndex(req.ID, "/")
+	if i < 0 {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Import ID %q must have the form project/environment/value_id/override_environment.", req.ID))
+		return
+	}
+	value, environment := req.ID[:i], req.ID[i+1:]
+	base, err := parseValueImportID(value)
+	if err != nil || base.Project == "" || environment == "" {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Import ID %q must have the form project/environment/value_id/override_environment.", req.ID))
+		return
+	}
+
+	scope := model.Scope{Project: base.Project, Environment: base.Environment}
+	override, err := o.c.GetValueOverride(ctx, scope, base.ValueID, environment)
+	if isNotFound(err) {
+		resp.Diagnostics.AddError("Cannot import non-existent value override", fmt.Sprintf("Value override %q does not exist.", req.ID))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading value override", err.Error())
+		return
+	}
+
+	// There is no prior state to reconcile with, so segments are referenced
+	// by their current refs, which configurations use.
+	imported := valueOverrideState(value, environment, override)
+	if err := o.c.resolveTargetingRefs(ctx, imported.Targeting); err != nil {
+		resp.Diagnostics.AddError("Error reading value override", err.Error())
+		return
+	}
+	diags := resp.State.Set(ctx, imported)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (o *ValueEnvironmentOverrideResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	o.c = req.ProviderData.(*config)
+}
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/jarcoal/httpmock"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+var (
+	//go:embed testdata/value_override.json
+	valueOverrideTestdata string
+	//go:embed testdata/value_override_drifted.json
+	valueOverrideDriftedTestdata string
+)
+
+func TestAccResourceWingsValueEnvironmentOverride(t *testing.T) {
+	const overrideURL = "http://localhost:8018/projects/payments/environments/staging/values/new-checkout/overrides/prod"
+
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, `{"version":"1.5.0","capabilities":["projects","environment_overrides","segments"]}`),
+	)
+	mock.RegisterResponder(
+		http.MethodPut,
+		overrideURL,
+		func(req *http.Request) (*http.Response, error) {
+			// Only the configured settings are sent, so the rest is inherited.
+			body, _ := io.ReadAll(req.Body)
+			if string(body) != `{"enabled":true,"defaultVariant":"on"}` {
+				return httpmock.NewStringResponse(400, "unexpected body: "+string(body)), nil
+			}
+			return httpmock.NewStringResponse(200, valueOverrideTestdata), nil
+		},
+	)
+	mock.RegisterResponder(
+		http.MethodGet,
+		overrideURL,
+		httpmock.NewStringResponder(200, valueOverrideTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		overrideURL,
+		httpmock.NewStringResponder(204, ""),
+	)
+	mock.RegisterResponder(
+		http.MethodPost,
+		"http://localhost:8018/segments",
+		httpmock.NewStringResponder(200, testSegmentJSON),
+	)
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/segments/internal",
+		httpmock.NewStringResponder(200, testSegmentJSON),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		"http://localhost:8018/segments/internal",
+		httpmock.NewStringResponder(204, ""),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceValueEnvironmentOverride("prod"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_value_environment_override.prod", "id", "payments/staging/new-checkout/prod"),
+					resource.TestCheckResourceAttr("wings_value_environment_override.prod", "enabled", "true"),
+					resource.TestCheckResourceAttr("wings_value_environment_override.prod", "default_variant", "on"),
+					resource.TestCheckResourceAttr("wings_value_environment_override.prod", "targeting.#", "0"),
+					resource.TestCheckResourceAttr("wings_value_environment_override.prod", "updated_by", "prod-owner"),
+				),
+			},
+			{
+				ResourceName:      "wings_value_environment_override.prod",
+				ImportState:       true,
+				ImportStateId:     "payments/staging/new-checkout/prod",
+				ImportStateVerify: true,
+			},
+			{
+				// Changes made outside of Terraform show up as drift.
+				PreConfig: func() {
+					mock.RegisterResponder(
+						http.MethodGet,
+						overrideURL,
+						httpmock.NewStringResponder(200, valueOverrideDriftedTestdata),
+					)
+				},
+				Config:             providerConfig + testAccResourceValueEnvironmentOverride("prod"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// The server reports segments by their bare key, and may
+				// reformat expressions, which is no difference to the refs
+				// and expressions in the configuration.
+				PreConfig: func() {
+					var (
+						mu     sync.Mutex
+						stored = valueOverrideTestdata
+					)
+					mock.RegisterResponder(http.MethodPut, overrideURL, func(req *http.Request) (*http.Response, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						override := new(model.ValueOverride)
+						if err := json.NewDecoder(req.Body).Decode(override); err != nil {
+							return nil, err
+						}
+						if override.Targeting != nil {
+							for i, r := range override.Targeting.Rules {
+								override.Targeting.Rules[i].Expr = strings.ReplaceAll(r.Expr, " ", "")
+							}
+						}
+						b, _ := json.Marshal(override)
+						stored = string(b)
+						return httpmock.NewStringResponse(200, stored), nil
+					})
+					mock.RegisterResponder(http.MethodGet, overrideURL, func(*http.Request) (*http.Response, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						return httpmock.NewStringResponse(200, stored), nil
+					})
+				},
+				Config: providerConfig + testAccResourceValueEnvironmentOverrideSegment(),
+				Check: resource.TestCheckResourceAttrPair(
+					"wings_value_environment_override.prod", "targeting.0.segment", "wings_segment.internal", "ref",
+				),
+			},
+			{
+				// The refreshed override matches the configuration.
+				Config:   providerConfig + testAccResourceValueEnvironmentOverrideSegment(),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "wings_value_environment_override.prod",
+				ImportState:       true,
+				ImportStateId:     "payments/staging/new-checkout/prod",
+				ImportStateVerify: true,
+				// The formatting of expressions is only known from the
+				// configuration.
+				ImportStateVerifyIgnore: []string{"targeting.1.expr"},
+			},
+		},
+	})
+}
+
+func TestAccResourceWingsValueEnvironmentOverride_BaseEnvironment(t *testing.T) {
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: httpmock.NewMockTransport(),
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccResourceValueEnvironmentOverride("staging"),
+				ExpectError: regexp.MustCompile("Override of the base environment"),
+			},
+		},
+	})
+}
+
+func testAccResourceValueEnvironmentOverride(environment string) string {
+	return `
+resource "wings_value_environment_override" "prod" {
+  value           = "payments/staging/new-checkout"
+  environment     = "` + environment + `"
+  enabled         = true
+  default_variant = "on"
+}`
+}
+
+const testSegmentJSON = `{"key":"internal","name":"Internal employees","description":"","expr":"email.endsWith('@example.com')"}`
+
+func testAccResourceValueEnvironmentOverrideSegment() string {
+	return `
+resource "wings_segment" "internal" {
+  key  = "internal"
+  name = "Internal employees"
+  expr = "email.endsWith('@example.com')"
+}
+
+resource "wings_value_environment_override" "prod" {
+  value           = "payments/staging/new-checkout"
+  environment     = "prod"
+  enabled         = true
+  default_variant = "on"
+
+  targeting {
+    variant = "on"
+    segment = wings_segment.internal.ref
+  }
+  targeting {
+    variant = "off"
+    expr    = "country == 'NL'"
+  }
+}`
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// reconcileValue merges the value returned by the server into prior, which is
+// either the plan that was just applied or the state being refreshed.
+//
+// Attributes the server returned in a semantically equivalent form, such as
+// reformatted object JSON or expressions with different whitespace, keep the
+// representation from prior so that Terraform does not report spurious
+// differences. Attributes the server actually rewrote take the server's
+// representation and are described in the returned list of changes.
+func reconcileValue(prior *valueResource, remote *model.Value) (*valueResource, []string) {
+	r := &valueReconciler{}
+	got := valueState(remote)
+
+	state := &valueResource{
+		ValueID:        r.string("value_id", prior.ValueID, got.ValueID, stringEquivalent),
+		Project:        prior.Project,
+		Environment:    prior.Environment,
+		Description:    r.string("description", prior.Description, got.Description, stringEquivalent),
+		Enabled:        r.bool("enabled", prior.Enabled, got.Enabled),
+		DefaultVariant: r.string("default_variant", prior.DefaultVariant, got.DefaultVariant, stringEquivalent),
+		AdoptExisting:  prior.AdoptExisting,
+		TargetingMode:  prior.TargetingMode,
+		ContextSchema:  prior.ContextSchema,
+		Bool: reconcileVariants(r, "bool", prior.Bool, got.Bool,
+			func(v valueResourceBool) string { return v.Variant.ValueString() },
+			func(path string, p, g valueResourceBool) valueResourceBool {
+				p.Value = r.bool(path+".value", p.Value, g.Value)
+				return p
+			},
+		),
+		Int: reconcileVariants(r, "int", prior.Int, got.Int,
+			func(v valueResourceInt) string { return v.Variant.ValueString() },
+			func(path string, p, g valueResourceInt) valueResourceInt {
+				p.Value = r.int64(path+".value", p.Value, g.Value)
+				return p
+			},
+		),
+		String: reconcileVariants(r, "string", prior.String, got.String,
+			func(v valueResourceString) string { return v.Variant.ValueString() },
+			func(path string, p, g valueResourceString) valueResourceString {
+				p.Value = r.string(path+".value", p.Value, g.Value, stringEquivalent)
+				return p
+			},
+		),
+		Object: reconcileVariants(r, "object", prior.Object, got.Object,
+			func(v valueResourceObject) string { return v.Variant.ValueString() },
+			func(path string, p, g valueResourceObject) valueResourceObject {
+				p.Value = r.string(path+".value", p.Value, g.Value, jsonEquivalent)
+				p.Transform = reconcileList(r, path+".transform", p.Transform, g.Transform,
+					func(path string, p, g valueResourceTransform) valueResourceTransform {
+						p.Expr = r.string(path+".expr", p.Expr, g.Expr, exprEquivalent)
+						return p
+					},
+				)
+				return p
+			},
+		),
+		Targeting: reconcileTargeting(r, prior.Targeting, got.Targeting),
+		Test: reconcileList(r, "test", prior.Test, got.Test,
+			func(path string, p, g valueResourceTest) valueResourceTest {
+				p.Variables = r.string(path+".variables", p.Variables, g.Variables, jsonEquivalent)
+				p.Expected = r.string(path+".expected", p.Expected, g.Expected, stringEquivalent)
+				return p
+			},
+		),
+		Prerequisite: reconcileList(r, "prerequisite", prior.Prerequisite, got.Prerequisite,
+			func(path string, p, g valueResourcePrerequisite) valueResourcePrerequisite {
+				p.ValueID = r.string(path+".value_id", p.ValueID, g.ValueID, stringEquivalent)
+				p.Variant = r.string(path+".variant", p.Variant, g.Variant, stringEquivalent)
+				return p
+			},
+		),
+	}
+	state.setMetadata(remote)
+	if !prior.ID.IsUnknown() && !prior.ID.IsNull() {
+		state.ID = prior.ID
+	}
+
+	return state, r.changes
+}
+
+// reconcileTargeting reconciles targeting blocks, which the server reports
+// with the bare keys of segments and with expressions it may reformat.
+func reconcileTargeting(r *valueReconciler, prior, got []valueResourceTargeting) []valueResourceTargeting {
+	return reconcileList(r, "targeting", prior, got,
+		func(path string, p, g valueResourceTargeting) valueResourceTargeting {
+			p.Variant = r.string(path+".variant", p.Variant, g.Variant, stringEquivalent)
+			p.Expr = r.string(path+".expr", p.Expr, g.Expr, exprEquivalent)
+			p.Segment = r.string(path+".segment", p.Segment, g.Segment, segmentRefEquivalent)
+			p.SegmentList = r.string(path+".segment_list", p.SegmentList, g.SegmentList, segmentRefEquivalent)
+			return p
+		},
+	)
+}
+
+type valueReconciler struct {
+	changes []string
+}
+
+func (r *valueReconciler) changed(path string, prior, got any) {
+	r.changes = append(r.changes, fmt.Sprintf("%s: %v -> %v", path, prior, got))
+}
+
+func (r *valueReconciler) string(path string, prior, got types.String, equivalent func(a, b string) bool) types.String {
+	// An omitted optional attribute is reported by the server as an empty string.
+	if prior.IsNull() && got.ValueString() == "" {
+		return prior
+	}
+	if equivalent(prior.ValueString(), got.ValueString()) {
+		return prior
+	}
+	r.changed(path, prior, got)
+	return got
+}
+
+func (r *valueReconciler) bool(path string, prior, got types.Bool) types.Bool {
+	if prior.ValueBool() == got.ValueBool() {
+		return prior
+	}
+	r.changed(path, prior, got)
+	return got
+}
+
+func (r *valueReconciler) int64(path string, prior, got types.Int64) types.Int64 {
+	if prior.ValueInt64() == got.ValueInt64() {
+		return prior
+	}
+	r.changed(path, prior, got)
+	return got
+}
+
+// reconcileVariants matches variants by name, keeping the order of prior.
+// Variants only known to the server are appended in name order.
+func reconcileVariants[T any](r *valueReconciler, block string, prior, got []T, name func(T) string, merge func(path string, p, g T) T) []T {
+	byName := make(map[string]int, len(got))
+	for i, g := range got {
+		byName[name(g)] = i
+	}
+
+	var merged []T
+	if prior != nil {
+		merged = make([]T, 0, len(prior))
+	}
+	seen := make(map[string]bool, len(got))
+	for _, p := range prior {
+		n := name(p)
+		i, ok := byName[n]
+		if !ok {
+			r.changes = append(r.changes, fmt.Sprintf("%s[%q]: removed by the server", block, n))
+			continue
+		}
+		seen[n] = true
+		merged = append(merged, merge(fmt.Sprintf("%s[%q]", block, n), p, got[i]))
+	}
+	for _, g := range got {
+		n := name(g)
+		if seen[n] {
+			continue
+		}
+		r.changes = append(r.changes, fmt.Sprintf("%s[%q]: added by the server", block, n))
+		merged = append(merged, g)
+	}
+	return merged
+}
+
+// reconcileList matches ordered blocks, such as targeting rules, by position.
+func reconcileList[T any](r *valueReconciler, block string, prior, got []T, merge func(path string, p, g T) T) []T {
+	var merged []T
+	if prior != nil || len(got) > 0 {
+		merged = make([]T, 0, max(len(prior), len(got)))
+	}
+	for i, p := range prior {
+		if i >= len(got) {
+			r.changes = append(r.changes, fmt.Sprintf("%s[%d]: removed by the server", block, i))
+			continue
+		}
+		merged = append(merged, merge(fmt.Sprintf("%s[%d]", block, i), p, got[i]))
+	}
+	for i := len(prior); i < len(got); i++ {
+		r.changes = append(r.changes, fmt.Sprintf("%s[%d]: added by the server", block, i))
+		merged = append(merged, got[i])
+	}
+	return merged
+}
+
+func stringEquivalent(a, b string) bool {
+	return a == b
+}
+
+// jsonEquivalent reports whether a and b encode the same JSON document,
+// ignoring formatting and object key order.
+func jsonEquivalent(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var x, y any
+	if err := json.Unmarshal([]byte(a), &x); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &y); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// exprEquivalent reports whether a and b are the same expression, ignoring
+// the whitespace between its tokens.
+func exprEquivalent(a, b string) bool {
+	return normalizeExpr(a) == normalizeExpr(b)
+}
+
+// normalizeExpr returns expr with its tokens separated by single spaces, so
+// that formatting does not matter but tokens are never merged.
+func normalizeExpr(expr string) string {
+	tokens := tokenizeExpr(expr)
+	texts := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		texts = append(texts, t.text)
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+func Test_ExprEquivalent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"env == 'dev'", "env=='dev'", true},
+		{" env == 'dev' ", "env == 'dev'", true},
+		{"userId in ['a', 'b']", "userId in ['a','b']", true},
+		{"env == 'dev '", "env == 'dev'", false},
+		{"a in b", "ain b", false},
+		{`name == "a \" b"`, `name=="a \" b"`, true},
+		{"env == 'dev'", "env == 'prd'", false},
+		{"x in y", "xiny", false},
+		{"a = = b", "a == b", false},
+		{"a & & b", "a && b", false},
+		// CEL has no -- operator, so both subtract the negation of b.
+		{"a - -b", "a--b", true},
+		{"a - - b", "a -- b", true},
+	}
+	for _, tt := range tests {
+		if got := exprEquivalent(tt.a, tt.b); got != tt.want {
+			t.Errorf("exprEquivalent(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func Test_JSONEquivalent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{`{"a":1,"b":[true,null]}`, "{\n  \"b\": [true, null],\n  \"a\": 1.0\n}", true},
+		{`{"a":1}`, `{"a":2}`, false},
+		{`{"a":[1,2]}`, `{"a":[2,1]}`, false},
+		{`not json`, `not json`, true},
+		{`not json`, `{}`, false},
+	}
+	for _, tt := range tests {
+		if got := jsonEquivalent(tt.a, tt.b); got != tt.want {
+			t.Errorf("jsonEquivalent(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func Test_ReconcileValue(t *testing.T) {
+	t.Parallel()
+
+	plan := &valueResource{
+		ID:             types.StringUnknown(),
+		ValueID:        types.StringValue("checkout"),
+		Description:    types.StringNull(),
+		Enabled:        types.BoolValue(true),
+		DefaultVariant: types.StringValue("off"),
+		Bool: []valueResourceBool{
+			{Variant: types.StringValue("on"), Value: types.BoolValue(true)},
+			{Variant: types.StringValue("off"), Value: types.BoolValue(false)},
+		},
+		Object: []valueResourceObject{
+			{
+				Variant:   types.StringValue("json"),
+				Value:     types.StringValue(`{"b":1,"a":"x"}`),
+				Transform: []valueResourceTransform{},
+			},
+		},
+		Targeting: []valueResourceTargeting{
+			{Variant: types.StringValue("on"), Expr: types.StringValue("env == 'dev'")},
+		},
+		Test: []valueResourceTest{},
+	}
+
+	t.Run("equivalent", func(t *testing.T) {
+		t.Parallel()
+
+		remote := &model.Value{
+			ID:             "checkout",
+			Enabled:        true,
+			DefaultVariant: "off",
+			Variants: model.Variants{
+				"off":  {Bool: &model.Bool{Value: false}},
+				"on":   {Bool: &model.Bool{Value: true}},
+				"json": {Object: &model.Object{Value: map[string]any{"a": "x", "b": 1}}},
+			},
+			Targeting: model.Targeting{
+				Rules: []model.ValueTargetingRule{{Variant: "on", Expr: "env=='dev'"}},
+			},
+			Revision: 2,
+		}
+
+		state, changes := reconcileValue(plan, remote)
+		if len(changes) != 0 {
+			t.Fatalf("unexpected changes: %v", changes)
+		}
+		if !state.Description.IsNull() {
+			t.Errorf("description = %v, want null", state.Description)
+		}
+		if got := state.Bool[0].Variant.ValueString(); got != "on" {
+			t.Errorf("bool[0].variant = %q, want the planned order", got)
+		}
+		if got := state.Object[0].Value.ValueString(); got != `{"b":1,"a":"x"}` {
+			t.Errorf("object value = %q, want the configured representation", got)
+		}
+		if got := state.Targeting[0].Expr.ValueString(); got != "env == 'dev'" {
+			t.Errorf("targeting expr = %q, want the configured representation", got)
+		}
+		if state.Test == nil {
+			t.Error("test = nil, want empty list")
+		}
+		if got := state.ID.ValueString(); got != "checkout" {
+			t.Errorf("id = %q, want checkout", got)
+		}
+		if got := state.Revision.ValueInt64(); got != 2 {
+			t.Errorf("revision = %d, want 2", got)
+		}
+	})
+
+	t.Run("rewritten", func(t *testing.T) {
+		t.Parallel()
+
+		remote := &model.Value{
+			ID:             "checkout",
+			Enabled:        true,
+			DefaultVariant: "on",
+			Variants: model.Variants{
+				"on":   {Bool: &model.Bool{Value: true}},
+				"json": {Object: &model.Object{Value: map[string]any{"a": "y", "b": 1}}},
+			},
+			Targeting: model.Targeting{
+				Rules: []model.ValueTargetingRule{
+					{Variant: "on", Expr: "env == 'dev'"},
+					{Variant: "on", Expr: "true"},
+				},
+			},
+		}
+
+		state, changes := reconcileValue(plan, remote)
+		want := []string{
+			`default_variant: "off" -> "on"`,
+			`bool["off"]: removed by the server`,
+			`object["json"].value: "{\"b\":1,\"a\":\"x\"}" -> "{\"a\":\"y\",\"b\":1}"`,
+			`targeting[1]: added by the server`,
+		}
+		if len(changes) != len(want) {
+			t.Fatalf("changes = %q, want %q", changes, want)
+		}
+		for i := range want {
+			if changes[i] != want[i] {
+				t.Errorf("changes[%d] = %q, want %q", i, changes[i], want[i])
+			}
+		}
+		if got := state.DefaultVariant.ValueString(); got != "on" {
+			t.Errorf("default_variant = %q, want the server's value", got)
+		}
+		if len(state.Bool) != 1 || len(state.Targeting) != 2 {
+			t.Errorf("bool = %d, targeting = %d, want 1 and 2", len(state.Bool), len(state.Targeting))
+		}
+	})
+}
@@ -0,0 +1,378 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+var (
+	_ resource.Resource                = &ValueTargetingRuleResource{}
+	_ resource.ResourceWithModifyPlan  = &ValueTargetingRuleResource{}
+	_ resource.ResourceWithImportState = &ValueTargetingRuleResource{}
+)
+
+func NewValueTargetingRuleResource() resource.Resource {
+	return &ValueTargetingRuleResource{}
+}
+
+type ValueTargetingRuleResource struct {
+	c *config
+}
+
+type valueTargetingRuleResource struct {
+	ID          types.String `tfsdk:"id"`
+	Value       types.String `tfsdk:"value"`
+	Name        types.String `tfsdk:"name"`
+	Priority    types.Int64  `tfsdk:"priority"`
+	Variant     types.String `tfsdk:"variant"`
+	Expr        types.String `tfsdk:"expr"`
+	Segment     types.String `tfsdk:"segment"`
+	SegmentList types.String `tfsdk:"segment_list"`
+}
+
+func (t *ValueTargetingRuleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_value_targeting_rule"
+}
+
+func (t *ValueTargetingRuleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Wings value targeting rule resource. Manages a single named targeting rule of a value that is managed elsewhere. " +
+			"Rules are evaluated in order and the first matching rule wins: the `targeting` blocks of the `wings_value` come first, " +
+			"followed by the rules of this resource in ascending `priority`, and then by `name`. " +
+			"Set `targeting_mode` of the `wings_value` to `shared` so that it keeps these rules.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The value and name of this Rule, as `value/name`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"value": schema.StringAttribute{
+				Description: "The `id` of the `wings_value` this Rule belongs to. Changing it replaces the Rule.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of this Rule, unique within its value. Changing it replaces the Rule.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[^/]+$`), "must not be empty or contain a slash"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"priority": schema.Int64Attribute{
+				Description: "The precedence of this Rule among the named rules of its value. Rules with a lower priority are evaluated first. Defaults to `0`.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(0),
+			},
+			"variant": schema.StringAttribute{
+				Description: "The variant served when the Rule matches.",
+				Required:    true,
+			},
+			"expr": schema.StringAttribute{
+				Description: "The expression the Rule matches with. Conflicts with `segment` and `segment_list`.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("segment"), path.MatchRoot("segment_list")),
+				},
+			},
+			"segment": schema.StringAttribute{
+				Description: "The `ref` of the `wings_segment` the Rule matches the members of. Conflicts with `expr` and `segment_list`.",
+				Optional:    true,
+			},
+			"segment_list": schema.StringAttribute{
+				Description: "The `ref` of the `wings_segment_list` the Rule matches the members of. Conflicts with `expr` and `segment`.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func (t *ValueTargetingRuleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || t.c == nil {
+		return
+	}
+	t.c.requireCapability(ctx, capabilityNamedTargetingRules, "standalone targeting rules", path.Root("name"), &resp.Diagnostics)
+}
+
+func (t *valueTargetingRuleResource) rule() model.ValueTargetingRule {
+	return model.ValueTargetingRule{
+		Variant:     t.Variant.ValueString(),
+		Expr:        t.Expr.ValueString(),
+		Segment:     segmentKey(t.Segment.ValueString()),
+		SegmentList: segmentKey(t.SegmentList.ValueString()),
+		Name:        t.Name.ValueString(),
+		Priority:    t.Priority.ValueInt64(),
+	}
+}
+
+// base returns the scope and value_id of the value of the rule.
+func (t *valueTargetingRuleResource) base() (model.Scope, string, error) {
+	id, err := parseValueImportID(t.Value.ValueString())
+	if err != nil {
+		return model.Scope{}, "", err
+	}
+	return model.Scope{Project: id.Project, Environment: id.Environment}, id.ValueID, nil
+}
+
+func valueTargetingRuleID(value, name string) string {
+	return value + "/" + name
+}
+
+// orderTargetingRules orders rules by precedence. The value's own rules keep
+// their order and come first, followed by named rules in ascending priority
+// and then name.
+func orderTargetingRules(rules []model.ValueTargetingRule) []model.ValueTargetingRule {
+	return slices.SortedStableFunc(slices.Values(rules), func(a, b model.ValueTargetingRule) int {
+		switch {
+		case a.Name == "" && b.Name == "":
+			return 0
+		case a.Name == "":
+			return -1
+		case b.Name == "":
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.Priority, b.Priority), cmp.Compare(a.Name, b.Name))
+	})
+}
+
+// ownTargetingRules returns the rules of a value that are not named, and so
+// not managed separately.
+func ownTargetingRules(rules []model.ValueTargetingRule) []model.ValueTargetingRule {
+	return slices.DeleteFunc(slices.Clone(rules), func(r model.ValueTargetingRule) bool {
+		return r.Name != ""
+	})
+}
+
+// namedTargetingRules returns the rules of a value that are managed
+// separately.
+func namedTargetingRules(rules []model.ValueTargetingRule) []model.ValueTargetingRule {
+	return slices.DeleteFunc(slices.Clone(rules), func(r model.ValueTargetingRule) bool {
+		return r.Name == ""
+	})
+}
+
+func findTargetingRule(rules []model.ValueTargetingRule, name string) int {
+	return slices.IndexFunc(rules, func(r model.ValueTargetingRule) bool {
+		return r.Name == name
+	})
+}
+
+func (t *ValueTargetingRuleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan valueTargetingRuleResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scope, id, err := plan.base()
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating targeting rule", "Invalid Attribute(s): "+err.Error())
+		return
+	}
+
+	rule := plan.rule()
+	_, err = t.c.ModifyValue(ctx, scope, id, func(current *model.Value) (*model.Value, error) {
+		if findTargetingRule(current.Targeting.Rules, rule.Name) >= 0 {
+			return nil, fmt.Errorf("value %q already has a targeting rule named %q; import it instead", plan.Value.ValueString(), rule.Name)
+		}
+		current.Targeting.Rules = orderTargetingRules(append(current.Targeting.Rules, rule))
+		return current, nil
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating targeting rule", err.Error())
+		return
+	}
+
+	plan.ID = types.StringValue(valueTargetingRuleID(plan.Value.ValueString(), rule.Name))
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (t *ValueTargetingRuleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state valueTargetingRuleResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scope, id, err := state.base()
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading targeting rule", err.Error())
+		return
+	}
+
+	value, err := t.c.GetValue(ctx, scope, id)
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Error reading targeting rule", err.Error())
+		return
+	}
+	i := findTargetingRule(value.Targeting.Rules, state.Name.ValueString())
+	if i < 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	rule := value.Targeting.Rules[i]
+	state.Priority = types.Int64Value(rule.Priority)
+	state.Variant = types.StringValue(rule.Variant)
+	if !exprEquivalent(state.Expr.ValueString(), rule.Expr) {
+		state.Expr = optionalString(rule.Expr)
+	}
+	if !segmentRefEquivalent(state.Segment.ValueString(), rule.Segment) {
+		state.Segment = optionalString(rule.Segment)
+	}
+	if !segmentRefEquivalent(state.SegmentList.ValueString(), rule.SegmentList) {
+		state.SegmentList = optionalString(rule.SegmentList)
+	}
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (t *ValueTargetingRuleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan valueTargetingRuleResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scope, id, err := plan.base()
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating targeting rule", "Invalid Attribute(s): "+err.Error())
+		return
+	}
+
+	rule := plan.rule()
+	_, err = t.c.ModifyValue(ctx, scope, id, func(current *model.Value) (*model.Value, error) {
+		i := findTargetingRule(current.Targeting.Rules, rule.Name)
+		if i < 0 {
+			return nil, fmt.Errorf("value %q has no targeting rule named %q", plan.Value.ValueString(), rule.Name)
+		}
+		current.Targeting.Rules[i] = rule
+		current.Targeting.Rules = orderTargetingRules(current.Targeting.Rules)
+		return current, nil
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating targeting rule", err.Error())
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (t *ValueTargetingRuleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state valueTargetingRuleResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scope, id, err := state.base()
+	if err != nil {
+		resp.Diagnostics.AddError("Error deleting targeting rule", err.Error())
+		return
+	}
+
+	name := state.Name.ValueString()
+	_, err = t.c.ModifyValue(ctx, scope, id, func(current *model.Value) (*model.Value, error) {
+		current.Targeting.Rules = slices.DeleteFunc(current.Targeting.Rules, func(r model.ValueTargetingRule) bool {
+			return r.Name == name
+		})
+		return current, nil
+	})
+	// The rule is gone with its value.
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Error deleting targeting rule", err.Error())
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+func (t *ValueTargetingRuleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	i := strings.LastIndex(req.ID, "/")
+	if i <= 0 || i == len(req.ID)-1 {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Import ID %q must have the form value/name, where value is the id of a wings_value.", req.ID))
+		return
+	}
+	state := valueTargetingRuleResource{
+		ID:    types.StringValue(req.ID),
+		Value: types.StringValue(req.ID[:i]),
+		Name:  types.StringValue(req.ID[i+1:]),
+	}
+	scope, id, err := state.base()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", err.Error())
+		return
+	}
+
+	value, err := t.c.GetValue(ctx, scope, id)
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("Error reading targeting rule", err.Error())
+		return
+	}
+	i = -1
+	if err == nil {
+		i = findTargetingRule(value.Targeting.Rules, state.Name.ValueString())
+	}
+	if i < 0 {
+		resp.Diagnostics.AddError("Cannot import non-existent targeting rule", fmt.Sprintf("Targeting rule %q does not exist.", req.ID))
+		return
+	}
+
+	rule := value.Targeting.Rules[i]
+	state.Priority = types.Int64Value(rule.Priority)
+	state.Variant = types.StringValue(rule.Variant)
+	state.Expr = optionalString(rule.Expr)
+	state.Segment = optionalString(rule.Segment)
+	state.SegmentList = optionalString(rule.SegmentList)
+
+	// There is no prior state to reconcile with, so segments are referenced
+	// by their current refs, which configurations use.
+	refs := []valueResourceTargeting{{Segment: state.Segment, SegmentList: state.SegmentList}}
+	if err := t.c.resolveTargetingRefs(ctx, refs); err != nil {
+		resp.Diagnostics.AddError("Error reading targeting rule", err.Error())
+		return
+	}
+	state.Segment, state.SegmentList = refs[0].Segment, refs[0].SegmentList
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (t *ValueTargetingRuleResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	t.c = req.ProviderData.(*config)
+}
@@ -0,0 +1,308 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jarcoal/httpmock"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// testValueServer stores a single value, and rejects updates of a revision
+// other than the current one like the Wings API does.
+type testValueServer struct {
+	mu    sync.Mutex
+	value *model.Value
+}
+
+func (s *testValueServer) register(mock *httpmock.MockTransport, u string) {
+	mock.RegisterResponder(http.MethodGet, u, func(*http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.value == nil {
+			return httpmock.NewStringResponse(404, "not found"), nil
+		}
+		return httpmock.NewJsonResponse(200, s.value)
+	})
+	mock.RegisterResponder(http.MethodPut, u, func(req *http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		value := new(model.Value)
+		if err := json.NewDecoder(req.Body).Decode(value); err != nil {
+			return nil, err
+		}
+		if value.Revision != 0 && value.Revision != s.value.Revision {
+			return httpmock.NewStringResponse(409, "revision mismatch"), nil
+		}
+		value.Revision = s.value.Revision + 1
+		s.value = value
+		return httpmock.NewJsonResponse(200, s.value)
+	})
+	mock.RegisterResponder(http.MethodDelete, u, func(*http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.value = nil
+		return httpmock.NewStringResponse(204, ""), nil
+	})
+}
+
+// registerCreate registers creating the value by posting it to u.
+func (s *testValueServer) registerCreate(mock *httpmock.MockTransport, u string) {
+	mock.RegisterResponder(http.MethodPost, u, func(req *http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		value := new(model.Value)
+		if err := json.NewDecoder(req.Body).Decode(value); err != nil {
+			return nil, err
+		}
+		value.Revision = 1
+		s.value = value
+		return httpmock.NewJsonResponse(200, value)
+	})
+}
+
+func (s *testValueServer) rules() []model.ValueTargetingRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.value == nil {
+		return nil
+	}
+	return slices.Clone(s.value.Targeting.Rules)
+}
+
+func TestAccResourceWingsValueTargetingRule(t *testing.T) {
+	server := &testValueServer{}
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, `{"version":"1.5.0","capabilities":["named_targeting_rules"]}`),
+	)
+	server.registerCreate(mock, "http://localhost:8018/values")
+	server.register(mock, "http://localhost:8018/values/checkout")
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	expectRules := func(want ...string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			var got []string
+			for _, r := range server.rules() {
+				got = append(got, fmt.Sprintf("%s:%s", r.Name, r.Expr))
+			}
+			if !slices.Equal(got, want) {
+				return fmt.Errorf("server rules = %q, want %q", got, want)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceValueTargetingRules("env == 'dev'"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_value_targeting_rule.late", "id", "checkout/late"),
+					resource.TestCheckResourceAttr("wings_value_targeting_rule.early", "priority", "0"),
+					resource.TestCheckResourceAttr("wings_value.checkout", "targeting.#", "1"),
+					// The rules of the value come first, then named rules by priority.
+					expectRules(":env == 'dev'", "early:country == 'NL'", "late:plan == 'beta'"),
+				),
+			},
+			{
+				ResourceName:      "wings_value_targeting_rule.late",
+				ImportState:       true,
+				ImportStateId:     "checkout/late",
+				ImportStateVerify: true,
+			},
+			{
+				// Updating the value in the shared mode keeps the named rules.
+				Config: providerConfig + testAccResourceValueTargetingRules("env == 'gamma'"),
+				Check:  expectRules(":env == 'gamma'", "early:country == 'NL'", "late:plan == 'beta'"),
+			},
+		},
+	})
+}
+
+func TestAccResourceWingsValueTargetingRule_Segment(t *testing.T) {
+	server := &testValueServer{}
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, `{"version":"1.5.0","capabilities":["named_targeting_rules","segments"]}`),
+	)
+	mock.RegisterResponder(http.MethodPost, "http://localhost:8018/segments", httpmock.NewStringResponder(200, testSegmentJSON))
+	mock.RegisterResponder(http.MethodGet, "http://localhost:8018/segments/internal", httpmock.NewStringResponder(200, testSegmentJSON))
+	mock.RegisterResponder(http.MethodDelete, "http://localhost:8018/segments/internal", httpmock.NewStringResponder(204, ""))
+	server.registerCreate(mock, "http://localhost:8018/values")
+	server.register(mock, "http://localhost:8018/values/checkout")
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceValueTargetingRuleSegment(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("wings_value_targeting_rule.internal", "segment", "wings_segment.internal", "ref"),
+					func(*terraform.State) error {
+						if rules := server.rules(); len(rules) != 1 || rules[0].Segment != "internal" {
+							return fmt.Errorf("server rules = %+v, want a rule of segment internal", rules)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// The imported rule references the segment by its ref, as the
+				// configuration does.
+				ResourceName:      "wings_value_targeting_rule.internal",
+				ImportState:       true,
+				ImportStateId:     "checkout/internal",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func Test_ModifyValue_Conflict(t *testing.T) {
+	server := &testValueServer{value: &model.Value{ID: "checkout", Revision: 7}}
+	mock := httpmock.NewMockTransport()
+	server.register(mock, "http://localhost:8018/values/checkout")
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	attempts := 0
+	updated, err := cfg.ModifyValue(context.Background(), model.Scope{}, "checkout", func(current *model.Value) (*model.Value, error) {
+		attempts++
+		if attempts == 1 {
+			// Another writer updates the value after it was read.
+			server.value = &model.Value{ID: "checkout", Revision: 8}
+		}
+		current.Description = "modified"
+		return current, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+	if updated.Description != "modified" || updated.Revision != 9 {
+		t.Errorf("updated = %+v, want the modification of revision 8", updated)
+	}
+}
+
+func Test_OrderTargetingRules(t *testing.T) {
+	rules := []model.ValueTargetingRule{
+		{Name: "b", Priority: 1},
+		{Expr: "own-1"},
+		{Name: "c", Priority: -1},
+		{Name: "a", Priority: 1},
+		{Expr: "own-2"},
+	}
+
+	var got []string
+	for _, r := range orderTargetingRules(rules) {
+		got = append(got, r.Name+r.Expr)
+	}
+	want := []string{"own-1", "own-2", "c", "a", "b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("orderTargetingRules() = %q, want %q", got, want)
+	}
+}
+
+func testAccResourceValueTargetingRules(expr string) string {
+	return fmt.Sprintf(`
+resource "wings_value" "checkout" {
+  value_id        = "checkout"
+  enabled         = true
+  default_variant = "off"
+  targeting_mode  = "shared"
+
+  bool {
+    variant = "on"
+    value   = true
+  }
+  bool {
+    variant = "off"
+    value   = false
+  }
+
+  targeting {
+    variant = "on"
+    expr    = %q
+  }
+}
+
+resource "wings_value_targeting_rule" "late" {
+  value    = wings_value.checkout.id
+  name     = "late"
+  priority = 10
+  variant  = "on"
+  expr     = "plan == 'beta'"
+}
+
+resource "wings_value_targeting_rule" "early" {
+  value   = wings_value.checkout.id
+  name    = "early"
+  variant = "on"
+  expr    = "country == 'NL'"
+}`, expr)
+}
+
+func testAccResourceValueTargetingRuleSegment() string {
+	return `
+resource "wings_segment" "internal" {
+  key  = "internal"
+  name = "Internal employees"
+  expr = "email.endsWith('@example.com')"
+}
+
+resource "wings_value" "checkout" {
+  value_id        = "checkout"
+  enabled         = true
+  default_variant = "off"
+  targeting_mode  = "shared"
+
+  bool {
+    variant = "on"
+    value   = true
+  }
+  bool {
+    variant = "off"
+    value   = false
+  }
+}
+
+resource "wings_value_targeting_rule" "internal" {
+  value   = wings_value.checkout.id
+  name    = "internal"
+  variant = "on"
+  segment = wings_segment.internal.ref
+}`
+}
@@ -2,11 +2,19 @@ package provider
 
 import (
 	_ "embed"
+	"fmt"
 	"net/http"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/jarcoal/httpmock"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
 )
 
 //go:embed testdata/bool.json
@@ -67,6 +75,78 @@ func TestAccResourceWingsValue_BoolValue(t *testing.T) {
 					resource.TestCheckResourceAttr("wings_value.test-bool-value", "test.#", "1"),
 					resource.TestCheckResourceAttr("wings_value.test-bool-value", "test.0.variables", "{\"count\":1,\"env\":\"test\"}"),
 					resource.TestCheckResourceAttr("wings_value.test-bool-value", "test.0.expected", "on"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "created_at", "2024-03-13T10:19:33Z"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "updated_at", "2024-03-14T08:00:00Z"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "updated_by", "ops@example.com"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "revision", "3"),
+				),
+			},
+			{
+				// Refreshing keeps the configured order even though the API returns variants as a map.
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "bool.0.variant", "on"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "bool.1.variant", "off"),
+				),
+			},
+			{
+				ResourceName:      "wings_value.test-bool-value",
+				ImportState:       true,
+				ImportStateId:     "test-bool-value",
+				ImportStateVerify: true,
+				// Without prior state, variants are ordered by name.
+				ImportStateVerifyIgnore: []string{"bool"},
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 imported state, got %d", len(states))
+					}
+					attrs := states[0].Attributes
+					if attrs["bool.0.variant"] != "off" || attrs["bool.1.variant"] != "on" {
+						return fmt.Errorf("expected variants ordered by name, got %q, %q", attrs["bool.0.variant"], attrs["bool.1.variant"])
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+//go:embed testdata/bool_normalized.json
+var boolNormalizedTestdata string
+
+func TestAccResourceWingsValue_NormalizedResponse(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/values/test-bool-value",
+		httpmock.NewStringResponder(200, boolNormalizedTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodPost,
+		"http://localhost:8018/values",
+		httpmock.NewStringResponder(200, boolNormalizedTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		"http://localhost:8018/values/test-bool-value",
+		httpmock.NewStringResponder(204, ""),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceBool(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "targeting.0.expr", "env == 'dev'"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "targeting.1.expr", "userId == 'XXX'"),
 				),
 			},
 		},
@@ -122,6 +202,9 @@ func TestAccResourceWingsValue_IntValue(t *testing.T) {
 					resource.TestCheckResourceAttr("wings_value.test-integer-value", "int.0.variant", "one"),
 					resource.TestCheckResourceAttr("wings_value.test-integer-value", "int.0.value", "1"),
 					resource.TestCheckResourceAttr("wings_value.test-integer-value", "targeting.#", "0"),
+					resource.TestCheckNoResourceAttr("wings_value.test-integer-value", "created_at"),
+					resource.TestCheckNoResourceAttr("wings_value.test-integer-value", "updated_by"),
+					resource.TestCheckNoResourceAttr("wings_value.test-integer-value", "revision"),
 				),
 			},
 		},
@@ -183,6 +266,392 @@ func TestAccResourceWingsValue_StringValue(t *testing.T) {
 	})
 }
 
+func TestAccResourceWingsValue_AdoptExisting(t *testing.T) {
+	existing := strings.Replace(stringTestdata, "test string value", "created by hand", 1)
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodPost,
+		"http://localhost:8018/values",
+		httpmock.NewStringResponder(409, `{"message":"value already exists"}`),
+	)
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/values/test-string-value",
+		func(req *http.Request) (*http.Response, error) {
+			if mock.GetCallCountInfo()["PUT http://localhost:8018/values/test-string-value"] == 0 {
+				return httpmock.NewStringResponse(200, existing), nil
+			}
+			return httpmock.NewStringResponse(200, stringTestdata), nil
+		},
+	)
+	mock.RegisterResponder(
+		http.MethodPut,
+		"http://localhost:8018/values/test-string-value",
+		httpmock.NewStringResponder(200, stringTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		"http://localhost:8018/values/test-string-value",
+		httpmock.NewStringResponder(204, ""),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+		adoptExisting: true,
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceString(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_value.test-string-value", "value_id", "test-string-value"),
+					resource.TestCheckResourceAttr("wings_value.test-string-value", "description", "test string value"),
+					func(*terraform.State) error {
+						if n := mock.GetCallCountInfo()["PUT http://localhost:8018/values/test-string-value"]; n != 1 {
+							return fmt.Errorf("expected the existing value to be updated once, got %d", n)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceWingsValue_AdoptExistingDisabled(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodPost,
+		"http://localhost:8018/values",
+		httpmock.NewStringResponder(409, `{"message":"value already exists"}`),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccResourceString(),
+				ExpectError: regexp.MustCompile("unexpected status code: 409"),
+			},
+		},
+	})
+}
+
+func TestAccResourceWingsValue_CollisionCheck(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/values/test-string-value",
+		httpmock.NewStringResponder(200, stringTestdata),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+		collisionCheck: collisionCheckError,
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccResourceString(),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Value already exists"),
+			},
+		},
+	})
+
+	if n := mock.GetCallCountInfo()["POST http://localhost:8018/values"]; n != 0 {
+		t.Errorf("expected no value to be created, got %d requests", n)
+	}
+}
+
+func TestAccResourceWingsValue_CollisionCheckReplace(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/values/test-string-value",
+		httpmock.NewStringResponder(200, stringTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/values/renamed-string-value",
+		httpmock.NewStringResponder(200, stringTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		"http://localhost:8018/values/test-string-value",
+		httpmock.NewStringResponder(204, ""),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+		collisionCheck: collisionCheckError,
+	}
+
+	renamed := strings.Replace(testAccResourceString(), `value_id = "test-string-value"`, `value_id = "renamed-string-value"`, 1)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config:             providerConfig + testAccResourceString(),
+				ResourceName:       "wings_value.test-string-value",
+				ImportState:        true,
+				ImportStateId:      "test-string-value",
+				ImportStatePersist: true,
+			},
+			{
+				// Changing value_id replaces the value with one that already exists.
+				Config:      providerConfig + renamed,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Value already exists"),
+			},
+		},
+	})
+
+	if n := mock.GetCallCountInfo()["POST http://localhost:8018/values"]; n != 0 {
+		t.Errorf("expected no value to be created, got %d requests", n)
+	}
+}
+
+func TestAccResourceWingsValue_Import(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/values/test-string-value",
+		httpmock.NewStringResponder(200, stringTestdata),
+	)
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/values/missing",
+		httpmock.NewStringResponder(404, `{"message":"not found"}`),
+	)
+	mock.RegisterResponder(
+		http.MethodDelete,
+		"http://localhost:8018/values/test-string-value",
+		httpmock.NewStringResponder(204, ""),
+	)
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config:        providerConfig + testAccResourceString(),
+				ResourceName:  "wings_value.test-string-value",
+				ImportState:   true,
+				ImportStateId: "missing",
+				ExpectError:   regexp.MustCompile("Cannot import non-existent value"),
+			},
+			{
+				Config:        providerConfig + testAccResourceString(),
+				ResourceName:  "wings_value.test-string-value",
+				ImportState:   true,
+				ImportStateId: "test-string-value/extra",
+				ExpectError:   regexp.MustCompile("Invalid import ID"),
+			},
+			{
+				Config:             providerConfig + testAccResourceString(),
+				ResourceName:       "wings_value.test-string-value",
+				ImportState:        true,
+				ImportStateId:      "test-string-value",
+				ImportStatePersist: true,
+			},
+			{
+				// The imported state matches the configuration, so there is nothing to plan.
+				Config:   providerConfig + testAccResourceString(),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceWingsValue_Scoped(t *testing.T) {
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, capabilitiesTestdata),
+	)
+	for _, env := range []string{"staging", "prod"} {
+		u := "http://localhost:8018/projects/payments/environments/" + env + "/values"
+		mock.RegisterResponder(http.MethodPost, u, httpmock.NewStringResponder(200, boolTestdata))
+		mock.RegisterResponder(http.MethodGet, u+"/test-bool-value", httpmock.NewStringResponder(200, boolTestdata))
+		mock.RegisterResponder(http.MethodPut, u+"/test-bool-value", httpmock.NewStringResponder(200, boolTestdata))
+		mock.RegisterResponder(http.MethodDelete, u+"/test-bool-value", httpmock.NewStringResponder(204, ""))
+	}
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+		scope: model.Scope{Project: "payments", Environment: "staging"},
+	}
+
+	prod := strings.Replace(testAccResourceBool(), `value_id = "test-bool-value"`, `value_id = "test-bool-value"
+  environment = "prod"`, 1)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceBool(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "id", "payments/staging/test-bool-value"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "project", "payments"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "environment", "staging"),
+				),
+			},
+			{
+				ResourceName:            "wings_value.test-bool-value",
+				ImportState:             true,
+				ImportStateId:           "payments/staging/test-bool-value",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"bool"},
+			},
+			{
+				// Bare value IDs are imported from the provider's scope.
+				ResourceName:            "wings_value.test-bool-value",
+				ImportState:             true,
+				ImportStateId:           "test-bool-value",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"bool"},
+			},
+			{
+				// Overriding the provider's environment moves the value to another environment.
+				Config: providerConfig + prod,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("wings_value.test-bool-value", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "id", "payments/prod/test-bool-value"),
+					resource.TestCheckResourceAttr("wings_value.test-bool-value", "environment", "prod"),
+				),
+			},
+		},
+	})
+
+	if n := mock.GetCallCountInfo()["POST http://localhost:8018/projects/payments/environments/prod/values"]; n != 1 {
+		t.Errorf("created the value in prod %d times, want 1", n)
+	}
+	if n := mock.GetCallCountInfo()["DELETE http://localhost:8018/projects/payments/environments/staging/values/test-bool-value"]; n != 1 {
+		t.Errorf("deleted the value from staging %d times, want 1", n)
+	}
+}
+
+func Test_ScopedValueID(t *testing.T) {
+	t.Parallel()
+
+	for _, scope := range []model.Scope{{}, {Project: "payments", Environment: "prod"}} {
+		id := scopedValueID(scope, "checkout")
+		parsed, err := parseValueImportID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := (model.Scope{Project: parsed.Project, Environment: parsed.Environment}); got != scope || parsed.ValueID != "checkout" {
+			t.Errorf("parseValueImportID(%q) = %+v, want scope %+v", id, parsed, scope)
+		}
+	}
+
+	c := &config{endpoint: "https://wings.example.com/api"}
+	u, err := c.scopedURL(model.Scope{Project: "payments", Environment: "prod"}, "values", "checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://wings.example.com/api/projects/payments/environments/prod/values/checkout"; u != want {
+		t.Errorf("scopedURL() = %q, want %q", u, want)
+	}
+}
+
+func Test_ValueStateOrdering(t *testing.T) {
+	t.Parallel()
+
+	value := &model.Value{
+		ID:       "ordering",
+		Variants: model.Variants{},
+	}
+	for _, name := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		value.Variants[name] = model.ValueEvaluation{String: &model.String{Value: name}}
+	}
+	value.Variants["int"] = model.ValueEvaluation{Int: &model.Int{Value: 1}}
+
+	for range 20 {
+		state := valueState(value)
+		var got []string
+		for _, v := range state.String {
+			got = append(got, v.Variant.ValueString())
+		}
+		want := []string{"alpha", "bravo", "charlie", "delta", "echo"}
+		if !slices.Equal(got, want) {
+			t.Fatalf("string variants = %v, want %v", got, want)
+		}
+		if len(state.Bool) != 0 || state.Bool == nil {
+			t.Fatalf("bool = %#v, want empty list", state.Bool)
+		}
+	}
+}
+
+func Test_ParseValueImportID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id      string
+		want    valueImportID
+		wantErr string
+	}{
+		{id: "checkout", want: valueImportID{ValueID: "checkout"}},
+		{id: "shop/prod/checkout", want: valueImportID{Project: "shop", Environment: "prod", ValueID: "checkout"}},
+		{id: "", wantErr: "must not contain empty segments"},
+		{id: "shop//checkout", wantErr: "must not contain empty segments"},
+		{id: "prod/checkout", wantErr: "has 2 segments"},
+		{id: "a/b/c/d", wantErr: "has 4 segments"},
+	}
+	for _, tt := range tests {
+		got, err := parseValueImportID(tt.id)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseValueImportID(%q) error = %v, want %q", tt.id, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseValueImportID(%q) unexpected error: %v", tt.id, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseValueImportID(%q) = %+v, want %+v", tt.id, got, tt.want)
+		}
+	}
+}
+
 //go:embed testdata/object.json
 var objectTestdata string
 
@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// Test_HMACSigningAuth_KnownAnswer checks a signature computed independently
+// with Python's hmac module.
+func Test_HMACSigningAuth_KnownAnswer(t *testing.T) {
+	t.Parallel()
+
+	auth := newHMACSigningAuth(&staticAPIKey{keyID: "key-id", key: "secret"})
+	auth.now = func() time.Time { return time.Unix(1710325173, 0) }
+	auth.nonce = func() (string, error) { return "00112233445566778899aabbccddeeff", nil }
+
+	req, err := http.NewRequest(http.MethodPut, "https://wings.example.com/api/values/check%20out?dry_run=true", strings.NewReader(`{"id":"checkout"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(headerKey, "must not be sent")
+	if err := auth.authenticate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		headerKeyID:         "key-id",
+		headerKey:           "",
+		headerTimestamp:     "1710325173",
+		headerExpires:       "1710325473",
+		headerNonce:         "00112233445566778899aabbccddeeff",
+		headerContentSHA256: "70d5a582d4ca78d2dd74503d0085a2c9f1b7b6c6e7cd860a5fb0c6bfc4b7f27b",
+		headerSignature:     "29e42733dbe06a85a031bd073cb43cd9364471873d7b7e97c05c8a3f0235d48a",
+	}
+	for h, v := range want {
+		if got := req.Header.Get(h); got != v {
+			t.Errorf("%s = %q, want %q", h, got, v)
+		}
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"id":"checkout"}` {
+		t.Errorf("body = %q, want it to still be readable after signing", body)
+	}
+}
+
+// signatureVerifier is a reference implementation of the server side of
+// request signing.
+type signatureVerifier struct {
+	keys map[string]string
+	now  func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]bool
+}
+
+func (v *signatureVerifier) verify(r *http.Request) error {
+	if r.Header.Get(headerKey) != "" {
+		return fmt.Errorf("raw API key sent")
+	}
+	key, ok := v.keys[r.Header.Get(headerKeyID)]
+	if !ok {
+		return fmt.Errorf("unknown key ID")
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	if hex.EncodeToString(sum[:]) != r.Header.Get(headerContentSHA256) {
+		return fmt.Errorf("body hash mismatch")
+	}
+
+	ts, err := strconv.ParseInt(r.Header.Get(headerTimestamp), 10, 64)
+	if err != nil {
+		return err
+	}
+	expires, err := strconv.ParseInt(r.Header.Get(headerExpires), 10, 64)
+	if err != nil {
+		return err
+	}
+	now := v.now().Unix()
+	if now < ts-30 || now > expires || expires-ts > int64(signatureValidity/time.Second) {
+		return fmt.Errorf("outside of the replay window")
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s",
+		"WINGS-HMAC-SHA256", r.Method, r.URL.EscapedPath(), r.URL.RawQuery,
+		r.Header.Get(headerTimestamp), r.Header.Get(headerExpires), r.Header.Get(headerNonce), r.Header.Get(headerContentSHA256))
+	sig, err := hex.DecodeString(r.Header.Get(headerSignature))
+	if err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	nonce := r.Header.Get(headerNonce)
+	if v.nonces[nonce] {
+		return fmt.Errorf("replayed nonce")
+	}
+	v.nonces[nonce] = true
+	return nil
+}
+
+func newSigningTestServer(t *testing.T, v *signatureVerifier, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.verify(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, err.Error())
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func Test_HMACSigningAuth_Verified(t *testing.T) {
+	t.Parallel()
+
+	v := &signatureVerifier{keys: map[string]string{"key-id": "secret"}, now: time.Now, nonces: map[string]bool{}}
+	srv := newSigningTestServer(t, v, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"checkout","enabled":true}`)
+	})
+
+	c := &config{
+		endpoint: srv.URL,
+		client:   srv.Client(),
+		auth:     newHMACSigningAuth(&staticAPIKey{keyID: "key-id", key: "secret"}),
+	}
+	for range 2 {
+		if _, err := c.CreateValue(context.Background(), &model.Value{ID: "checkout", Enabled: true}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c.auth = newHMACSigningAuth(&staticAPIKey{keyID: "key-id", key: "wrong"})
+	if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err == nil || !strings.Contains(err.Error(), "signature mismatch") {
+		t.Errorf("error = %v, want signature mismatch", err)
+	}
+
+	expired := newHMACSigningAuth(&staticAPIKey{keyID: "key-id", key: "secret"})
+	expired.now = func() time.Time { return time.Now().Add(-signatureValidity - time.Minute) }
+	c.auth = expired
+	if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err == nil || !strings.Contains(err.Error(), "replay window") {
+		t.Errorf("error = %v, want the replay window to be enforced", err)
+	}
+}
+
+func Test_HMACSigningAuth_RetriesAreResigned(t *testing.T) {
+	t.Parallel()
+
+	v := &signatureVerifier{keys: map[string]string{"key-id": "secret"}, now: time.Now, nonces: map[string]bool{}}
+	var attempts int
+	srv := newSigningTestServer(t, v, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id":"checkout"}`)
+	})
+
+	auth := newHMACSigningAuth(&staticAPIKey{keyID: "key-id", key: "secret"})
+	retryClient := retryablehttp.NewClient()
+	retryClient.Logger = nil
+	retryClient.RetryWaitMin = time.Millisecond
+	retryClient.RetryWaitMax = time.Millisecond
+	retryClient.PrepareRetry = func(req *http.Request) error {
+		return auth.authenticate(req.Context(), req)
+	}
+	c := &config{
+		endpoint: srv.URL,
+		client:   retryClient.StandardClient(),
+		auth:     auth,
+	}
+
+	if _, err := c.UpdateValue(context.Background(), &model.Value{ID: "checkout"}); err != nil {
+		t.Fatalf("retry was rejected: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
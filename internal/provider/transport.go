@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	tlsVersion12 = "1.2"
+	tlsVersion13 = "1.3"
+)
+
+var tlsVersions = map[string]uint16{
+	tlsVersion12: tls.VersionTLS12,
+	tlsVersion13: tls.VersionTLS13,
+}
+
+// unixSocketHost stands in for the host of unix:// endpoints, so that API
+// URLs can still be built by joining paths onto the endpoint.
+const unixSocketHost = "wings.sock"
+
+// transportOptions customize the HTTP transport used to reach Wings.
+type transportOptions struct {
+	caCertPEM          string
+	caCertFile         string
+	clientCertPEM      string
+	clientKeyPEM       string
+	minTLSVersion      string
+	insecureSkipVerify bool
+
+	proxyURL   string
+	unixSocket string
+}
+
+func newTransport(opts *transportOptions) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	switch {
+	case opts.unixSocket != "" && opts.proxyURL != "":
+		return nil, errors.New("proxy_url cannot be used with a unix:// endpoint")
+	case opts.unixSocket != "":
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", opts.unixSocket)
+		}
+	case opts.proxyURL != "":
+		proxy, err := parseProxyURL(opts.proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return transport, nil
+}
+
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy_url %q must use the http, https, socks5 or socks5h scheme", proxyURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy_url %q has no host", proxyURL)
+	}
+	return u, nil
+}
+
+// splitUnixEndpoint turns a unix:///path/to/wings.sock endpoint into an HTTP
+// endpoint on a placeholder host, returning the socket to dial. Other
+// endpoints are returned unchanged.
+func splitUnixEndpoint(endpoint string) (httpEndpoint, socket string, err error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "unix" {
+		return endpoint, "", nil
+	}
+	if u.Host != "" {
+		return "", "", fmt.Errorf("unix endpoint %q must have an absolute socket path, such as unix:///var/run/wings.sock", endpoint)
+	}
+	if u.Path == "" {
+		return "", "", fmt.Errorf("unix endpoint %q has no socket path", endpoint)
+	}
+	return "http://" + unixSocketHost, u.Path, nil
+}
+
+func newTLSConfig(opts *transportOptions) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.insecureSkipVerify,
+	}
+
+	if opts.minTLSVersion != "" {
+		v, ok := tlsVersions[opts.minTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported minimum TLS version %q", opts.minTLSVersion)
+		}
+		cfg.MinVersion = v
+	}
+
+	if opts.caCertPEM != "" || opts.caCertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if opts.caCertPEM != "" && !pool.AppendCertsFromPEM([]byte(opts.caCertPEM)) {
+			return nil, errors.New("ca_cert_pem does not contain any PEM encoded certificates")
+		}
+		if opts.caCertFile != "" {
+			b, err := os.ReadFile(opts.caCertFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading ca_cert_file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(b) {
+				return nil, fmt.Errorf("ca_cert_file %s does not contain any PEM encoded certificates", opts.caCertFile)
+			}
+		}
+		cfg.RootCAs = pool
+	}
+
+	if opts.clientCertPEM != "" || opts.clientKeyPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(opts.clientCertPEM), []byte(opts.clientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,375 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// testPKI is a throwaway CA with a server and a client certificate issued by
+// it, all PEM encoded.
+type testPKI struct {
+	caPEM      string
+	serverCert tls.Certificate
+	clientPEM  string
+	clientKey  string
+	pool       *x509.CertPool
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Wings Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "wings"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	serverPEM, serverKey := issue(2, x509.ExtKeyUsageServerAuth)
+	serverCert, err := tls.X509KeyPair(serverPEM, serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPEM, clientKey := issue(3, x509.ExtKeyUsageClientAuth)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return &testPKI{
+		caPEM:      string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+		serverCert: serverCert,
+		clientPEM:  string(clientPEM),
+		clientKey:  string(clientKey),
+		pool:       pool,
+	}
+}
+
+// newTestTLSServer starts a server presenting the test PKI's certificate,
+// configured further by configure.
+func newTestTLSServer(t *testing.T, pki *testPKI, configure func(*tls.Config)) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{pki.serverCert}}
+	if configure != nil {
+		configure(srv.TLS)
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getWithTransport(t *testing.T, url string, opts *transportOptions) error {
+	t.Helper()
+
+	transport, err := newTransport(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transport.CloseIdleConnections()
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func Test_Transport_TLS(t *testing.T) {
+	t.Parallel()
+
+	pki := newTestPKI(t)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte(pki.caPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	plain := newTestTLSServer(t, pki, nil)
+	mutual := newTestTLSServer(t, pki, func(c *tls.Config) {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+		c.ClientCAs = pki.pool
+	})
+	legacy := newTestTLSServer(t, pki, func(c *tls.Config) {
+		c.MaxVersion = tls.VersionTLS12
+	})
+
+	tests := []struct {
+		name    string
+		url     string
+		opts    transportOptions
+		wantErr string
+	}{
+		{
+			name:    "unknown authority",
+			url:     plain.URL,
+			wantErr: "certificate signed by unknown authority",
+		},
+		{
+			name: "ca_cert_pem",
+			url:  plain.URL,
+			opts: transportOptions{caCertPEM: pki.caPEM},
+		},
+		{
+			name: "ca_cert_file",
+			url:  plain.URL,
+			opts: transportOptions{caCertFile: caFile},
+		},
+		{
+			name: "insecure_skip_verify",
+			url:  plain.URL,
+			opts: transportOptions{insecureSkipVerify: true},
+		},
+		{
+			name: "client certificate",
+			url:  mutual.URL,
+			opts: transportOptions{caCertPEM: pki.caPEM, clientCertPEM: pki.clientPEM, clientKeyPEM: pki.clientKey},
+		},
+		{
+			name:    "missing client certificate",
+			url:     mutual.URL,
+			opts:    transportOptions{caCertPEM: pki.caPEM},
+			wantErr: "certificate",
+		},
+		{
+			name: "TLS 1.2 server",
+			url:  legacy.URL,
+			opts: transportOptions{caCertPEM: pki.caPEM},
+		},
+		{
+			name:    "TLS 1.2 server with minimum version 1.3",
+			url:     legacy.URL,
+			opts:    transportOptions{caCertPEM: pki.caPEM, minTLSVersion: tlsVersion13},
+			wantErr: "protocol version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := getWithTransport(t, tt.url, &tt.opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_NewTLSConfig_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]transportOptions{
+		"does not contain any PEM":   {caCertPEM: "not a certificate"},
+		"reading ca_cert_file":       {caCertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"loading client certificate": {clientCertPEM: "not a certificate", clientKeyPEM: "not a key"},
+		"unsupported minimum TLS":    {minTLSVersion: "1.1"},
+	}
+	for want, opts := range tests {
+		if _, err := newTLSConfig(&opts); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want %q", err, want)
+		}
+	}
+}
+
+func Test_Transport_UnixSocket(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "wings.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets are not supported: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/values/checkout" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"id":"checkout","enabled":true}`)
+	}))
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	endpoint, gotSocket, err := splitUnixEndpoint("unix://" + socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSocket != socket {
+		t.Errorf("socket = %q, want %q", gotSocket, socket)
+	}
+	transport, err := newTransport(&transportOptions{unixSocket: gotSocket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config{endpoint: endpoint, client: &http.Client{Transport: transport}}
+	value, err := c.GetValue(context.Background(), model.Scope{}, "checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value.ID != "checkout" || !value.Enabled {
+		t.Errorf("GetValue() = %+v", value)
+	}
+}
+
+func Test_SplitUnixEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		endpoint   string
+		wantHTTP   string
+		wantSocket string
+		wantErr    bool
+	}{
+		{endpoint: "https://wings.example.com/api", wantHTTP: "https://wings.example.com/api"},
+		{endpoint: "unix:///var/run/wings.sock", wantHTTP: "http://" + unixSocketHost, wantSocket: "/var/run/wings.sock"},
+		{endpoint: "unix://var/run/wings.sock", wantErr: true},
+		{endpoint: "unix://", wantErr: true},
+	}
+	for _, tt := range tests {
+		gotHTTP, gotSocket, err := splitUnixEndpoint(tt.endpoint)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitUnixEndpoint(%q) = %q, %q, want error", tt.endpoint, gotHTTP, gotSocket)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitUnixEndpoint(%q) unexpected error: %v", tt.endpoint, err)
+			continue
+		}
+		if gotHTTP != tt.wantHTTP || gotSocket != tt.wantSocket {
+			t.Errorf("splitUnixEndpoint(%q) = %q, %q, want %q, %q", tt.endpoint, gotHTTP, gotSocket, tt.wantHTTP, tt.wantSocket)
+		}
+	}
+}
+
+func Test_Transport_Proxy(t *testing.T) {
+	t.Parallel()
+
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.String())
+		fmt.Fprint(w, `{"id":"checkout"}`)
+	}))
+	t.Cleanup(proxy.Close)
+
+	transport, err := newTransport(&transportOptions{proxyURL: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config{endpoint: "http://wings.internal.example.com/api", client: &http.Client{Transport: transport}}
+	if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err != nil {
+		t.Fatal(err)
+	}
+	if got := proxied.Load(); got != "http://wings.internal.example.com/api/values/checkout" {
+		t.Errorf("proxied request = %v, want it to go through the proxy", got)
+	}
+
+	for _, opts := range []transportOptions{
+		{proxyURL: "ftp://proxy.example.com"},
+		{proxyURL: "http://"},
+		{proxyURL: proxy.URL, unixSocket: "/var/run/wings.sock"},
+	} {
+		if _, err := newTransport(&opts); err == nil {
+			t.Errorf("newTransport(%+v) succeeded, want error", opts)
+		}
+	}
+}
+
+func Test_Config_Headers(t *testing.T) {
+	t.Parallel()
+
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		fmt.Fprint(w, `{"id":"checkout"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := &config{
+		ua:       "terraform-provider-wings",
+		endpoint: srv.URL,
+		client:   srv.Client(),
+		auth:     &apiKeyAuth{source: &staticAPIKey{keyID: "id", key: "key"}},
+		headers:  map[string]string{"X-Route": "blue", headerUA: "custom"},
+	}
+	if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("X-Route") != "blue" {
+		t.Errorf("X-Route = %q, want blue", got.Get("X-Route"))
+	}
+	if got.Get(headerUA) != "terraform-provider-wings" || got.Get(headerKey) != "key" {
+		t.Errorf("custom headers overrode the provider's own: %v", got)
+	}
+
+	if !isReservedHeader("x-api-key") || !isReservedHeader("authorization") || isReservedHeader("X-Route") {
+		t.Error("isReservedHeader does not match reserved headers case-insensitively")
+	}
+}
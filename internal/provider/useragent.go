@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"strings"
+)
+
+const headerRequestID = "X-Request-ID"
+
+const envUserAgentSuffix = "WINGS_USER_AGENT_SUFFIX"
+
+// userAgent builds the User-Agent of the provider, such as
+// "terraform-provider-wings/1.2.0 terraform/1.9.5 pipeline/payments".
+func userAgent(providerVersion, terraformVersion, suffix string) string {
+	parts := []string{"terraform-provider-wings/" + providerVersion}
+	if terraformVersion != "" {
+		parts = append(parts, "terraform/"+terraformVersion)
+	}
+	if suffix = strings.TrimSpace(suffix); suffix != "" {
+		parts = append(parts, suffix)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+func Test_UserAgent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		providerVersion, terraformVersion, suffix string
+		want                                      string
+	}{
+		{"1.2.0", "1.9.5", "", "terraform-provider-wings/1.2.0 terraform/1.9.5"},
+		{"1.2.0", "1.9.5", " pipeline/payments ", "terraform-provider-wings/1.2.0 terraform/1.9.5 pipeline/payments"},
+		{"dev", "", "", "terraform-provider-wings/dev"},
+	}
+	for _, tt := range tests {
+		if got := userAgent(tt.providerVersion, tt.terraformVersion, tt.suffix); got != tt.want {
+			t.Errorf("userAgent(%q, %q, %q) = %q, want %q", tt.providerVersion, tt.terraformVersion, tt.suffix, got, tt.want)
+		}
+	}
+}
+
+func Test_Config_RequestID(t *testing.T) {
+	t.Parallel()
+
+	var requestIDs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get(headerRequestID))
+		if len(requestIDs) == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id":"checkout"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	retryClient := retryablehttp.NewClient()
+	retryClient.Logger = nil
+	retryClient.RetryWaitMin = time.Millisecond
+	retryClient.RetryWaitMax = time.Millisecond
+	c := &config{endpoint: srv.URL, client: retryClient.StandardClient(), runID: "0a1b2c"}
+
+	for range 2 {
+		if _, err := c.GetValue(context.Background(), model.Scope{}, "checkout"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"0a1b2c-1", "0a1b2c-2", "0a1b2c-2"}; !slices.Equal(requestIDs, want) {
+		t.Errorf("request IDs = %q, want %q", requestIDs, want)
+	}
+}
@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+// plannedValues records the prerequisites and variants of the values planned
+// in this run, so that cycles are also found between values that do not
+// exist on the server yet, or whose prerequisites are about to change. Values
+// that are planned to be destroyed, and those read from the server, are
+// recorded too, so that each value is read at most once per run.
+type plannedValues struct {
+	mu      sync.Mutex
+	values  map[string]plannedValue
+	removed map[string]bool
+	remote  map[string]remoteValue
+}
+
+type plannedValue struct {
+	prerequisites []model.ValuePrerequisite
+	variants      []string
+}
+
+// remoteValue is a value read from the server, which found reports whether
+// it exists.
+type remoteValue struct {
+	value plannedValue
+	found bool
+}
+
+func (p *plannedValues) set(id string, value plannedValue) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.values == nil {
+		p.values = map[string]plannedValue{}
+	}
+	p.values[id] = value
+}
+
+// remove records that the value id is planned to be destroyed.
+func (p *plannedValues) remove(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.removed == nil {
+		p.removed = map[string]bool{}
+	}
+	p.removed[id] = true
+}
+
+// lookup returns the value id as planned in this run, and otherwise as read
+// from the server by fetch, unless it is planned to be destroyed. Reads are
+// cached for the run, and a read that failed for another reason than the
+// value not existing only returns its error the first time.
+func (p *plannedValues) lookup(id string, fetch func() (plannedValue, error)) (plannedValue, bool, error) {
+	p.mu.Lock()
+	if value, ok := p.values[id]; ok {
+		p.mu.Unlock()
+		return value, true, nil
+	}
+	if p.removed[id] {
+		p.mu.Unlock()
+		return plannedValue{}, false, nil
+	}
+	if r, ok := p.remote[id]; ok {
+		p.mu.Unlock()
+		return r.value, r.found, nil
+	}
+	p.mu.Unlock()
+
+	value, err := fetch()
+	r := remoteValue{value: value, found: err == nil}
+	if isNotFound(err) {
+		err = nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.remote == nil {
+		p.remote = map[string]remoteValue{}
+	}
+	if cached, ok := p.remote[id]; ok {
+		// Another plan read the value concurrently and reported any error.
+		return cached.value, cached.found, nil
+	}
+	p.remote[id] = r
+	return r.value, r.found, err
+}
+
+// checkPrerequisites checks that the prerequisites of the planned value serve
+// the required variants, and that they do not depend on the value in turn.
+// Values are looked up among the values planned in this run first, and on
+// the server otherwise, unless they are planned to be destroyed.
+func (v *ValueResource) checkPrerequisites(ctx context.Context, resp *resource.ModifyPlanResponse) {
+	var plan valueResource
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !plan.prerequisitesKnown() {
+		return
+	}
+	if len(plan.Prerequisite) > 0 {
+		v.c.requireCapability(ctx, capabilityPrerequisites, "prerequisites", path.Root("prerequisite"), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	scope := plan.scope()
+	id := plan.ValueID.ValueString()
+	planned := plannedValue{variants: plan.variants()}
+	for _, p := range plan.Prerequisite {
+		planned.prerequisites = append(planned.prerequisites, p.prerequisite())
+	}
+	v.c.planned.set(scopedValueID(scope, id), planned)
+
+	lookup := func(id string) (plannedValue, bool) {
+		value, ok, err := v.c.planned.lookup(scopedValueID(scope, id), func() (plannedValue, error) {
+			value, err := v.c.GetValue(ctx, scope, id)
+			if err != nil {
+				return plannedValue{}, err
+			}
+			return plannedValue{
+				prerequisites: value.Prerequisites,
+				variants:      slices.Sorted(maps.Keys(value.Variants)),
+			}, nil
+		})
+		if err != nil {
+			resp.Diagnostics.AddWarning("Unable to check prerequisite", fmt.Sprintf("Reading value %q failed: %s", id, err))
+		}
+		return value, ok
+	}
+
+	for i, p := range planned.prerequisites {
+		valueIDPath := path.Root("prerequisite").AtListIndex(i).AtName("value_id")
+		if p.ValueID == id {
+			resp.Diagnostics.AddAttributeError(valueIDPath, "Prerequisite cycle", fmt.Sprintf("Value %q cannot be its own prerequisite.", id))
+			continue
+		}
+		// Prerequisites that are neither planned yet nor on the server may be
+		// created later in this run, so they are not an error.
+		prerequisite, ok := lookup(p.ValueID)
+		if !ok {
+			continue
+		}
+		if prerequisite.variants != nil && !slices.Contains(prerequisite.variants, p.Variant) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("prerequisite").AtListIndex(i).AtName("variant"),
+				"Unknown prerequisite variant",
+				fmt.Sprintf("Value %q has no variant %q. Its variants are %s.", p.ValueID, p.Variant, strings.Join(prerequisite.variants, ", ")),
+			)
+		}
+		if cycle := findPrerequisiteCycle(id, p.ValueID, lookup); cycle != nil {
+			resp.Diagnostics.AddAttributeError(
+				valueIDPath,
+				"Prerequisite cycle",
+				fmt.Sprintf("The prerequisites of value %q form a cycle: %s.", id, strings.Join(cycle, " -> ")),
+			)
+		}
+	}
+}
+
+// findPrerequisiteCycle returns the path of prerequisites from prerequisite
+// back to id, starting and ending at id, or nil if there is none.
+func findPrerequisiteCycle(id, prerequisite string, lookup func(id string) (plannedValue, bool)) []string {
+	visited := map[string]bool{}
+	var visit func(current string, trail []string) []string
+	visit = func(current string, trail []string) []string {
+		trail = append(trail, current)
+		if current == id {
+			return trail
+		}
+		if visited[current] {
+			return nil
+		}
+		visited[current] = true
+		value, ok := lookup(current)
+		if !ok {
+			return nil
+		}
+		for _, p := range value.prerequisites {
+			if cycle := visit(p.ValueID, slices.Clone(trail)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit(prerequisite, []string{id})
+}
+
+// prerequisitesKnown reports whether the ID, scope and prerequisites of the
+// value are known, which checking its prerequisites needs.
+func (v *valueResource) prerequisitesKnown() bool {
+	if v.ValueID.IsUnknown() || v.Project.IsUnknown() || v.Environment.IsUnknown() {
+		return false
+	}
+	for _, p := range v.Prerequisite {
+		if p.ValueID.IsUnknown() || p.Variant.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// variants returns the names of the variants of the value, or nil if any of
+// them is unknown.
+func (v *valueResource) variants() []string {
+	var names []types.String
+	for _, b := range v.Bool {
+		names = append(names, b.Variant)
+	}
+	for _, i := range v.Int {
+		names = append(names, i.Variant)
+	}
+	for _, s := range v.String {
+		names = append(names, s.Variant)
+	}
+	for _, o := range v.Object {
+		names = append(names, o.Variant)
+	}
+
+	variants := make([]string, 0, len(names))
+	for _, n := range names {
+		if n.IsUnknown() {
+			return nil
+		}
+		variants = append(variants, n.ValueString())
+	}
+	slices.Sort(variants)
+	return variants
+}
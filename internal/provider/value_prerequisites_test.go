@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jarcoal/httpmock"
+
+	"fantech.dev/terraform-provider-wings/internal/model"
+)
+
+func TestAccResourceWingsValuePrerequisite(t *testing.T) {
+	servers := map[string]*testValueServer{
+		"checkout": {},
+		"payments": {},
+	}
+	mock := httpmock.NewMockTransport()
+	mock.RegisterResponder(
+		http.MethodGet,
+		"http://localhost:8018/capabilities",
+		httpmock.NewStringResponder(200, `{"version":"1.5.0","capabilities":["prerequisites"]}`),
+	)
+	mock.RegisterResponder(http.MethodPost, "http://localhost:8018/values", func(req *http.Request) (*http.Response, error) {
+		value := new(model.Value)
+		if err := json.NewDecoder(req.Body).Decode(value); err != nil {
+			return nil, err
+		}
+		s := servers[value.ID]
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		value.Revision = 1
+		s.value = value
+		return httpmock.NewJsonResponse(200, value)
+	})
+	for id, s := range servers {
+		s.register(mock, "http://localhost:8018/values/"+id)
+	}
+
+	cfg := &config{
+		endpoint: "http://localhost:8018",
+		client: &http.Client{
+			Transport: mock,
+		},
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(cfg),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccResourceValuePrerequisite("on", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("wings_value.checkout", "prerequisite.0.value_id", "payments"),
+					resource.TestCheckResourceAttr("wings_value.checkout", "prerequisite.0.variant", "on"),
+					func(*terraform.State) error {
+						servers["checkout"].mu.Lock()
+						defer servers["checkout"].mu.Unlock()
+						want := []model.ValuePrerequisite{{ValueID: "payments", Variant: "on"}}
+						if got := servers["checkout"].value.Prerequisites; !slices.Equal(got, want) {
+							return fmt.Errorf("server prerequisites = %+v, want %+v", got, want)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config:      providerConfig + testAccResourceValuePrerequisite("enabled", ""),
+				ExpectError: regexp.MustCompile(`Value "payments" has no variant "enabled"`),
+			},
+			{
+				Config: providerConfig + testAccResourceValuePrerequisite("on", `
+  prerequisite {
+    value_id = "checkout"
+    variant  = "on"
+  }`),
+				ExpectError: regexp.MustCompile(`Prerequisite cycle`),
+			},
+		},
+	})
+}
+
+func Test_FindPrerequisiteCycle(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b"},
+		"b": {"c", "d"},
+		"c": {},
+		"d": {"e", "a"},
+		"e": {"e"},
+	}
+	lookup := func(id string) (plannedValue, bool) {
+		prerequisites, ok := graph[id]
+		if !ok {
+			return plannedValue{}, false
+		}
+		value := plannedValue{}
+		for _, p := range prerequisites {
+			value.prerequisites = append(value.prerequisites, model.ValuePrerequisite{ValueID: p, Variant: "on"})
+		}
+		return value, true
+	}
+
+	tests := []struct {
+		id, prerequisite string
+		want             string
+	}{
+		{id: "a", prerequisite: "b", want: "a -> b -> d -> a"},
+		{id: "d", prerequisite: "a", want: "d -> a -> b -> d"},
+		{id: "c", prerequisite: "missing"},
+		// Cycles that do not lead back to id are not its problem.
+		{id: "b", prerequisite: "e"},
+	}
+	for _, tt := range tests {
+		got := strings.Join(findPrerequisiteCycle(tt.id, tt.prerequisite, lookup), " -> ")
+		if got != tt.want {
+			t.Errorf("findPrerequisiteCycle(%q, %q) = %q, want %q", tt.id, tt.prerequisite, got, tt.want)
+		}
+	}
+}
+
+func Test_PlannedValuesLookup(t *testing.T) {
+	var planned plannedValues
+	planned.set("planned", plannedValue{variants: []string{"on"}})
+	planned.remove("destroyed")
+
+	reads := map[string]int{}
+	fetch := func(id string, err error) func() (plannedValue, error) {
+		return func() (plannedValue, error) {
+			reads[id]++
+			if err != nil {
+				return plannedValue{}, err
+			}
+			return plannedValue{variants: []string{id}}, nil
+		}
+	}
+	failed := errors.New("connection reset")
+	notFound := &apiError{StatusCode: http.StatusNotFound}
+
+	for i := range 2 {
+		if _, ok, _ := planned.lookup("planned", fetch("planned", nil)); !ok {
+			t.Error("lookup() did not find the planned value")
+		}
+		if _, ok, _ := planned.lookup("destroyed", fetch("destroyed", nil)); ok {
+			t.Error("lookup() found a value planned to be destroyed")
+		}
+		if value, ok, err := planned.lookup("remote", fetch("remote", nil)); !ok || err != nil || !slices.Equal(value.variants, []string{"remote"}) {
+			t.Errorf("lookup() of a value on the server = %v, %t, %v", value, ok, err)
+		}
+		if _, ok, err := planned.lookup("missing", fetch("missing", notFound)); ok || err != nil {
+			t.Errorf("lookup() of a missing value = %t, %v, want not found", ok, err)
+		}
+		_, ok, err := planned.lookup("failing", fetch("failing", failed))
+		if ok {
+			t.Error("lookup() found a value that failed to be read")
+		}
+		if want := i == 0; (err != nil) != want {
+			t.Errorf("lookup() #%d of a value that failed to be read returned error %v", i+1, err)
+		}
+	}
+
+	want := map[string]int{"remote": 1, "missing": 1, "failing": 1}
+	if !maps.Equal(reads, want) {
+		t.Errorf("lookup() read %v, want %v", reads, want)
+	}
+}
+
+func testAccResourceValuePrerequisite(variant, paymentsPrerequisites string) string {
+	return fmt.Sprintf(`
+resource "wings_value" "payments" {
+  value_id        = "payments"
+  enabled         = true
+  default_variant = "off"
+
+  bool {
+    variant = "on"
+    value   = true
+  }
+  bool {
+    variant = "off"
+    value   = false
+  }
+%s
+}
+
+resource "wings_value" "checkout" {
+  value_id        = "checkout"
+  enabled         = true
+  default_variant = "off"
+
+  bool {
+    variant = "on"
+    value   = true
+  }
+  bool {
+    variant = "off"
+    value   = false
+  }
+
+  prerequisite {
+    value_id = "payments"
+    variant  = %q
+  }
+}`, paymentsPrerequisites, variant)
+}